@@ -0,0 +1,188 @@
+package animeinfo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/editprompt"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// Same keys as the list: c for completion, +/- for episodes, and a prompt for everything else. The finish date is on F though, since f scrolls the page
+
+// Sent once tracking has been changed, so the panel can be reloaded. The notice is shown too, if there is one
+type savedMessage struct {
+	notice string
+}
+
+func (m Model) loadTracking() tea.Msg {
+	anime, err := m.dbConfig.DB.GetAnime(m.dbConfig.Ctx, m.id)
+	if err == sql.ErrNoRows {
+		return TrackingMessage{}
+	}
+	if err != nil {
+		return types.ErrorMsg(err.Error())
+	}
+	return TrackingMessage{Anime: &anime}
+}
+
+// Adding needs the title, so it has to wait for the details to load. Anything already in the list just changes completion
+func (m *Model) openCompletionSelector() {
+	if m.tracking == nil && m.anime.MalID == 0 {
+		return
+	}
+
+	current := ""
+	if m.tracking != nil {
+		current = m.tracking.Completion
+	}
+	completion.Select(&m.completionSelector, current)
+	m.showCompletion = true
+}
+
+// Called with whatever was picked in the completion popup
+func (m *Model) pickCompletion(picked string) tea.Cmd {
+	if m.tracking != nil {
+		return m.updateCmd(func(q *database.Queries, updated string) error {
+			return q.UpdateAnimeCompletion(m.dbConfig.Ctx, database.UpdateAnimeCompletionParams{
+				Completion:  picked,
+				Updateddate: updated,
+				ID:          m.id,
+			})
+		})
+	}
+
+	// Not in the list yet, so ask for a start date before adding it
+	m.addCompletion = picked
+	date := dates.Unknown
+	if picked != types.PlanToWatch {
+		date = "today"
+	}
+	m.edit.Start(editprompt.AddStartDate, date)
+	return nil
+}
+
+// Opens the edit prompt for an anime in the list, prefilled with the current value
+func (m *Model) startEdit(field editprompt.Field) {
+	if m.tracking == nil {
+		return
+	}
+	m.edit.Start(field, editprompt.Value(field, *m.tracking))
+}
+
+// Validates the prompt and returns a command saving it, or an error to show under the prompt
+func (m Model) submitEdit() (tea.Cmd, error) {
+	switch m.edit.Field {
+	case editprompt.Episodes:
+		n, err := m.edit.Number()
+		if err != nil {
+			return nil, err
+		}
+		if m.anime.Episodes > 0 && n > int64(m.anime.Episodes) {
+			return nil, fmt.Errorf("only %d episodes", m.anime.Episodes)
+		}
+		return m.setEpisodesCmd(n), nil
+	case editprompt.Score:
+		n, err := m.edit.Number()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(q *database.Queries, updated string) error {
+			return q.UpdateAnimeScore(m.dbConfig.Ctx, database.UpdateAnimeScoreParams{
+				Score:       n,
+				Updateddate: updated,
+				ID:          m.id,
+			})
+		}), nil
+	case editprompt.AddStartDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.addCmd(m.addCompletion, date), nil
+	case editprompt.StartDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(q *database.Queries, updated string) error {
+			return q.UpdateAnimeStartDate(m.dbConfig.Ctx, database.UpdateAnimeStartDateParams{
+				Startdate:   date,
+				Updateddate: updated,
+				ID:          m.id,
+			})
+		}), nil
+	case editprompt.FinishDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(q *database.Queries, updated string) error {
+			return q.UpdateAnimeFinishDate(m.dbConfig.Ctx, database.UpdateAnimeFinishDateParams{
+				Finishdate:  date,
+				Updateddate: updated,
+				ID:          m.id,
+			})
+		}), nil
+	}
+
+	return nil, nil
+}
+
+// Sets the episode count, capped at however many episodes there are
+func (m Model) setEpisodesCmd(episodes int64) tea.Cmd {
+	if m.tracking == nil {
+		return nil
+	}
+
+	episodes = max(0, episodes)
+	if m.anime.Episodes > 0 {
+		episodes = min(episodes, int64(m.anime.Episodes))
+	}
+	if episodes == m.tracking.Watchedepisodes {
+		return nil
+	}
+
+	return m.updateCmd(func(q *database.Queries, updated string) error {
+		return q.UpdateAnimeEpisodes(m.dbConfig.Ctx, database.UpdateAnimeEpisodesParams{
+			Watchedepisodes: episodes,
+			Updateddate:     updated,
+			ID:              m.id,
+		})
+	})
+}
+
+func (m Model) addCmd(completion string, startDate sql.NullString) tea.Cmd {
+	anime := m.anime
+	return func() tea.Msg {
+		if err := m.dbConfig.AddAnime(int64(anime.MalID), anime.Title, completion, startDate); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return savedMessage{notice: fmt.Sprintf("Added %s as %s", anime.Title, completion)}
+	}
+}
+
+// Runs an update with today's date in a transaction, then reloads the tracking panel
+func (m Model) updateCmd(update func(q *database.Queries, updated string) error) tea.Cmd {
+	return func() tea.Msg {
+		tx, err := m.dbConfig.Conn.BeginTx(m.dbConfig.Ctx, nil)
+		if err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		defer tx.Rollback()
+
+		if err := update(m.dbConfig.DB.WithTx(tx), time.Now().Format("2006-01-02")); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		if err := tx.Commit(); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+
+		return savedMessage{}
+	}
+}
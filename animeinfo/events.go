@@ -0,0 +1,18 @@
+package animeinfo
+
+import (
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// The user's own tracking state for the anime being shown, or nil if it isn't in their list
+type TrackingMessage struct {
+	Anime *database.Anime
+}
+
+// Newer details for an anime that was shown from the cache. It can arrive after the page has moved on to another anime, so it's ignored unless ID
+// matches
+type RefreshedMessage struct {
+	ID    int64
+	Anime types.AnimeData
+}
@@ -7,18 +7,32 @@ import (
 type KeyMap struct {
 	Esc  key.Binding
 	Help key.Binding
+
+	NextSection key.Binding
+	PrevSection key.Binding
+	Toggle      key.Binding
+
+	Completion        key.Binding
+	IncrementEpisodes key.Binding
+	DecrementEpisodes key.Binding
+	EditEpisodes      key.Binding
+	EditScore         key.Binding
+	EditStartDate     key.Binding
+	EditFinishDate    key.Binding
 }
 
 // ShortHelp implements the KeyMap interface.
 func (km KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{km.Esc, km.Help}
+	return []key.Binding{km.Esc, km.NextSection, km.Toggle, km.Help}
 }
 
 // FullHelp implements the KeyMap interface.
 func (km KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{km.Esc},
-		{km.Help},
+		{km.Esc, km.Help},
+		{km.NextSection, km.PrevSection, km.Toggle},
+		{km.Completion, km.IncrementEpisodes, km.DecrementEpisodes},
+		{km.EditEpisodes, km.EditScore, km.EditStartDate, km.EditFinishDate},
 	}
 }
 
@@ -31,4 +45,44 @@ var AnimeInfoKeyMap = KeyMap{
 		key.WithKeys("?"),
 		key.WithHelp("?", "toggle help"),
 	),
+	NextSection: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "next section"),
+	),
+	PrevSection: key.NewBinding(
+		key.WithKeys("shift+tab"),
+		key.WithHelp("shift+tab", "previous section"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "expand/collapse"),
+	),
+	Completion: key.NewBinding(
+		key.WithKeys("c", "a"),
+		key.WithHelp("c/a", "add or set completion"),
+	),
+	IncrementEpisodes: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "watched episode"),
+	),
+	DecrementEpisodes: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "unwatch episode"),
+	),
+	EditEpisodes: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "set episodes"),
+	),
+	EditScore: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rate"),
+	),
+	EditStartDate: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "set start date"),
+	),
+	EditFinishDate: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "set finish date"),
+	),
 }
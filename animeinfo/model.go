@@ -1,19 +1,27 @@
 package animeinfo
 
 import (
-	"log"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/editprompt"
+	"github.com/saubuny/haru/internal/database"
 	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/overlay"
 	"github.com/saubuny/haru/types"
 )
 
+// The page is a header with genre chips, then the collapsible sections scrolling in a viewport on the left and the fact panels on the right.
+// Tracking can be changed from here too, with the edit prompt taking the place of the help at the bottom
+
 var titleStyle = func() lipgloss.Style {
 	b := lipgloss.RoundedBorder()
 	b.Right = "├"
@@ -22,67 +30,231 @@ var titleStyle = func() lipgloss.Style {
 
 func (m Model) headerView(name string) string {
 	title := titleStyle.Render(name)
-	line := strings.Repeat("─", max(0, int(float64(m.width)*0.8)-lipgloss.Width(title)))
+	line := strings.Repeat("─", max(0, m.pageWidth()-lipgloss.Width(title)))
 	return lipgloss.JoinHorizontal(lipgloss.Center, title, line)
 }
 
 type Model struct {
 	width  int
 	height int
-	title  string
 
-	help     help.Model
-	viewport viewport.Model
-	spinner  spinner.Model
+	dbConfig db.DBConfig
+	id       int64
+	anime    types.AnimeData
+	tracking *database.Anime
+
+	// Sections in the viewport, which one tab has moved to, and the line each one starts on
+	sections []section
+	focused  int
+	offsets  []int
+
+	help               help.Model
+	viewport           viewport.Model
+	spinner            spinner.Model
+	completionSelector list.Model
+
+	showSpinner    bool
+	showHelp       bool
+	showCompletion bool
+	failed         bool
 
-	showSpinner bool
-	showHelp    bool
+	// Completion picked for an anime being added, while its start date is asked for
+	addCompletion string
+
+	edit editprompt.Model
 }
 
-func New() Model {
+// Info page for the anime with the given MAL ID. The details arrive later in a types.AnimeDataMessage, but tracking is loaded straight from the DB
+func New(db db.DBConfig, id int64) Model {
 	help := help.New()
+	help.ShowAll = true
 
 	s := spinner.New()
 	s.Spinner = spinner.Points
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	return Model{
-		help:        help,
-		viewport:    viewport.New(0, 0),
-		showHelp:    true,
-		spinner:     s,
-		showSpinner: true,
+		dbConfig:           db,
+		id:                 id,
+		help:               help,
+		viewport:           viewport.New(0, 0),
+		completionSelector: completion.NewSelector(),
+		edit:               editprompt.New(),
+		showHelp:           true,
+		spinner:            s,
+		showSpinner:        true,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.spinner.Tick
+	return tea.Batch(m.spinner.Tick, m.loadTracking)
+}
+
+func (m Model) pageWidth() int {
+	return int(float64(m.width) * 0.8)
+}
+
+// Sizes the viewport around everything else on the page and rerenders the sections into it
+func (m *Model) layout() {
+	m.viewport.Width = max(0, m.pageWidth()-panelWidth-4)
+
+	used := 6
+	if m.showHelp {
+		used += lipgloss.Height(m.help.View(AnimeInfoKeyMap))
+	}
+	if chips := chipsView(m.anime, m.pageWidth()); chips != "" {
+		used += lipgloss.Height(chips)
+	}
+	m.viewport.Height = max(0, m.height-used)
+
+	var content string
+	content, m.offsets = renderSections(m.sections, m.focused, m.viewport.Width)
+	m.viewport.SetContent(content)
+}
+
+// Moves focus to another section and scrolls to it
+func (m *Model) focus(i int) {
+	if len(m.sections) == 0 {
+		return
+	}
+
+	m.focused = (i + len(m.sections)) % len(m.sections)
+	m.layout()
+	m.viewport.SetYOffset(m.offsets[m.focused])
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case types.ErrorMsg:
-		log.Fatalf("Error: %v", msg)
+		// The error itself is shown in the status bar by navstack
+		if m.anime.MalID == 0 {
+			m.failed = true
+		}
+		m.showSpinner = false
+		return m, nil
+	case RefreshedMessage:
+		if msg.ID != m.id {
+			return m, nil
+		}
+		return m.Update(types.AnimeDataMessage{Data: msg.Anime})
 	case types.AnimeDataMessage:
-		m.title = msg.Data.Title
-		content := msg.Data.Synopsis
-		m.viewport.SetContent(content)
+		// A slow load for a page that's already been left can arrive here, since only the top screen gets messages
+		if m.id != 0 && int64(msg.Data.MalID) != m.id {
+			return m, nil
+		}
+
+		sections := newSections(msg.Data)
+		if msg.Data.MalID == m.anime.MalID {
+			sections = keepCollapsed(m.sections, sections)
+		}
+
+		m.failed = false
+		m.anime = msg.Data
+		m.sections = sections
+		m.focused = min(m.focused, max(0, len(sections)-1))
 		m.showSpinner = false
+
+		offset := m.viewport.YOffset
+		m.layout()
+		m.viewport.SetYOffset(offset)
+		return m, nil
+	case savedMessage:
+		if msg.notice == "" {
+			return m, m.loadTracking
+		}
+		return m, tea.Batch(m.loadTracking, navstack.Cmd(navstack.Notify(msg.notice)))
+	case TrackingMessage:
+		m.tracking = msg.Anime
 		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.viewport.Width = int(float64(m.width) * 0.8)
-		m.viewport.Height = m.height - 6
+		m.edit.Input.Width = m.pageWidth() / 3
+		m.layout()
 		return m, nil
 	case tea.KeyMsg:
+		if m.showCompletion {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.showCompletion = false
+				return m, nil
+			case tea.KeyEnter:
+				m.showCompletion = false
+				if item, ok := m.completionSelector.SelectedItem().(completion.Item); ok {
+					return m, m.pickCompletion(string(item))
+				}
+				return m, nil
+			}
+			m.completionSelector, cmd = m.completionSelector.Update(msg)
+			return m, cmd
+		}
+
+		if m.edit.Editing() {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.edit.Stop()
+				return m, nil
+			case tea.KeyEnter:
+				save, err := m.submitEdit()
+				if err != nil {
+					m.edit.Err = err.Error()
+					return m, nil
+				}
+				m.edit.Stop()
+				return m, save
+			}
+			m.edit, cmd = m.edit.Update(msg)
+			return m, cmd
+		}
+
+		switch {
+		case key.Matches(msg, AnimeInfoKeyMap.Completion):
+			m.openCompletionSelector()
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.IncrementEpisodes):
+			if m.tracking != nil {
+				return m, m.setEpisodesCmd(m.tracking.Watchedepisodes + 1)
+			}
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.DecrementEpisodes):
+			if m.tracking != nil {
+				return m, m.setEpisodesCmd(m.tracking.Watchedepisodes - 1)
+			}
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.EditEpisodes):
+			m.startEdit(editprompt.Episodes)
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.EditScore):
+			m.startEdit(editprompt.Score)
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.EditStartDate):
+			m.startEdit(editprompt.StartDate)
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.EditFinishDate):
+			m.startEdit(editprompt.FinishDate)
+			return m, nil
+		}
+
 		switch {
 		case key.Matches(msg, AnimeInfoKeyMap.Esc):
 			pop := navstack.Cmd(navstack.PopNavigation{})
 			return m, pop
 		case key.Matches(msg, AnimeInfoKeyMap.Help):
 			m.showHelp = !m.showHelp
+			m.layout()
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.NextSection):
+			m.focus(m.focused + 1)
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.PrevSection):
+			m.focus(m.focused - 1)
+			return m, nil
+		case key.Matches(msg, AnimeInfoKeyMap.Toggle):
+			if len(m.sections) > 0 {
+				m.sections[m.focused].collapsed = !m.sections[m.focused].collapsed
+				m.focus(m.focused)
+			}
 			return m, nil
 		}
 	}
@@ -103,12 +275,31 @@ func (m Model) View() string {
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, render)
 	}
 
-	render += m.headerView(m.title) + "\n"
-	render += m.viewport.View() + "\n"
+	if m.failed {
+		render = "Couldn't load this anime. Press ctrl+r to try again, or esc to go back"
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, render)
+	}
+
+	render += m.headerView(m.anime.Title) + "\n"
+	if chips := chipsView(m.anime, m.pageWidth()); chips != "" {
+		render += lipgloss.NewStyle().Width(m.pageWidth()).Render(chips) + "\n"
+	}
 
-	if m.showHelp {
+	panels := lipgloss.JoinVertical(lipgloss.Left, trackingView(m.tracking, m.anime), factsView(m.anime))
+	panels = lipgloss.NewStyle().MaxHeight(m.viewport.Height).Render(panels)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(m.viewport.Width).Render(m.viewport.View()), "  ", panels)
+	render += body + "\n"
+
+	if m.edit.Editing() {
+		render += m.edit.View() + "\n"
+	} else if m.showHelp {
 		render += m.help.View(AnimeInfoKeyMap)
 	}
 
-	return lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+	render = lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+	if m.showCompletion {
+		render = overlay.Center(completion.View(m.completionSelector), render)
+	}
+
+	return render
 }
@@ -0,0 +1,40 @@
+package animeinfo
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/types"
+)
+
+func TestRefreshedMessage(t *testing.T) {
+	var m tea.Model = New(db.DBConfig{}, 1)
+	m, _ = m.Update(types.AnimeDataMessage{Data: types.AnimeData{MalID: 1, Title: "Cached"}})
+
+	// Left over from a page that was open before this one
+	m, _ = m.Update(RefreshedMessage{ID: 2, Anime: types.AnimeData{MalID: 2, Title: "Other"}})
+	if title := m.(Model).anime.Title; title != "Cached" {
+		t.Fatalf("expected a refresh for another anime to be ignored, got %q", title)
+	}
+
+	m, _ = m.Update(RefreshedMessage{ID: 1, Anime: types.AnimeData{MalID: 1, Title: "Refreshed"}})
+	if title := m.(Model).anime.Title; title != "Refreshed" {
+		t.Fatalf("expected the refresh to replace the cached details, got %q", title)
+	}
+}
+
+func TestAnimeDataForAnotherPage(t *testing.T) {
+	var m tea.Model = New(db.DBConfig{}, 1)
+
+	// Still loading an anime from the page before
+	m, _ = m.Update(types.AnimeDataMessage{Data: types.AnimeData{MalID: 2, Title: "Other", Episodes: 12}})
+	if got := m.(Model); got.anime.MalID != 0 || !got.showSpinner {
+		t.Fatalf("expected details for another anime to be ignored, got %#v", got.anime)
+	}
+
+	m, _ = m.Update(types.AnimeDataMessage{Data: types.AnimeData{MalID: 1, Title: "Cowboy Bebop", Episodes: 26}})
+	if got := m.(Model); got.anime.Title != "Cowboy Bebop" || got.showSpinner {
+		t.Fatalf("expected the details to be shown, got %#v", got.anime)
+	}
+}
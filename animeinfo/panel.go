@@ -0,0 +1,217 @@
+package animeinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+const panelWidth = 36
+
+var (
+	panelStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Width(panelWidth)
+	headingStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	labelStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	genreChip       = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	themeChip       = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("24"))
+	demographicChip = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("95"))
+)
+
+type fact struct {
+	label string
+	value string
+}
+
+// The facts shown next to the synopsis. Anything Jikan didn't have is left out
+func facts(anime types.AnimeData) []fact {
+	episodes := "?"
+	if anime.Episodes > 0 {
+		episodes = strconv.Itoa(anime.Episodes)
+	}
+
+	all := []fact{
+		{"Type", anime.Type},
+		{"Episodes", episodes},
+		{"Status", anime.Status},
+		{"Aired", aired(anime)},
+		{"Season", strings.TrimSpace(capitalise(anime.Season) + " " + nonZero(anime.Year))},
+		{"Broadcast", anime.Broadcast.String},
+		{"Duration", anime.Duration},
+		{"Source", anime.Source},
+		{"Rating", anime.Rating},
+		{"Score", score(anime)},
+		{"Ranked", rank(anime.Rank)},
+		{"Popularity", rank(anime.Popularity)},
+		{"Members", commas(anime.Members)},
+		{"Studios", names(anime.Studios)},
+	}
+
+	shown := []fact{}
+	for _, f := range all {
+		if f.value != "" && f.value != "Unknown" {
+			shown = append(shown, f)
+		}
+	}
+	return shown
+}
+
+func factsView(anime types.AnimeData) string {
+	lines := []string{headingStyle.Render("Details")}
+	for _, f := range facts(anime) {
+		lines = append(lines, labelStyle.Render(f.label+": ")+f.value)
+	}
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// What the user has tracked for this anime, if it's in their list
+func trackingView(tracking *database.Anime, anime types.AnimeData) string {
+	if tracking == nil {
+		return panelStyle.Render(headingStyle.Render("Your list") + "\n" + labelStyle.Render("Not in your list"))
+	}
+
+	episodes := strconv.Itoa(int(tracking.Watchedepisodes))
+	if anime.Episodes > 0 {
+		episodes += " / " + strconv.Itoa(anime.Episodes)
+	}
+	score := "-"
+	if tracking.Score > 0 {
+		score = strconv.Itoa(int(tracking.Score))
+	}
+
+	lines := []string{
+		headingStyle.Render("Your list"),
+		labelStyle.Render("Completion: ") + tracking.Completion,
+		labelStyle.Render("Episodes: ") + episodes,
+		labelStyle.Render("Score: ") + score,
+		labelStyle.Render("Started: ") + dates.Display(tracking.Startdate),
+		labelStyle.Render("Finished: ") + dates.Display(tracking.Finishdate),
+		labelStyle.Render("Rewatches: ") + strconv.Itoa(int(tracking.Timeswatched)),
+	}
+	return panelStyle.Render(strings.Join(lines, "\n"))
+}
+
+// Genres, themes and demographics as coloured chips, wrapped to fit in width
+func chipsView(anime types.AnimeData, width int) string {
+	chips := []string{}
+	for _, g := range anime.Genres {
+		chips = append(chips, genreChip.Render(g.Name))
+	}
+	for _, g := range anime.Themes {
+		chips = append(chips, themeChip.Render(g.Name))
+	}
+	for _, g := range anime.Demographics {
+		chips = append(chips, demographicChip.Render(g.Name))
+	}
+
+	lines := []string{}
+	line := ""
+	for _, chip := range chips {
+		if line != "" && lipgloss.Width(line)+1+lipgloss.Width(chip) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += chip
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Jikan's aired dates are full timestamps, but only the day matters
+func aired(anime types.AnimeData) string {
+	from, to := day(anime.Aired.From), day(anime.Aired.To)
+	switch {
+	case from == "":
+		return ""
+	case to == "" && anime.Airing:
+		return from + " to now"
+	case to == "" || to == from:
+		return from
+	}
+	return from + " to " + to
+}
+
+func day(timestamp string) string {
+	if len(timestamp) < len("2006-01-02") {
+		return ""
+	}
+	return timestamp[:len("2006-01-02")]
+}
+
+func score(anime types.AnimeData) string {
+	if anime.Score == 0 {
+		return ""
+	}
+	if anime.ScoredBy == 0 {
+		return fmt.Sprintf("%.2f", anime.Score)
+	}
+	return fmt.Sprintf("%.2f (%s users)", anime.Score, commas(anime.ScoredBy))
+}
+
+func rank(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return "#" + commas(n)
+}
+
+// Same shape as the studios, genres etc. in types.AnimeData
+type entity = struct {
+	MalID int    `json:"mal_id"`
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+}
+
+func names(named []entity) string {
+	all := []string{}
+	for _, n := range named {
+		all = append(all, n.Name)
+	}
+	return strings.Join(all, ", ")
+}
+
+func nonZero(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func capitalise(s string) string {
+	if s == "" {
+		return ""
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// 1234567 -> 1,234,567
+func commas(n int) string {
+	if n == 0 {
+		return ""
+	}
+
+	digits := strconv.Itoa(n)
+	out := ""
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out += ","
+		}
+		out += string(d)
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package animeinfo
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/types"
+)
+
+var (
+	sectionStyle        = lipgloss.NewStyle().Bold(true)
+	focusedSectionStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+)
+
+// A collapsible part of the scrolling text, like the synopsis
+type section struct {
+	title     string
+	body      string
+	collapsed bool
+}
+
+// Only the synopsis is open to begin with, the rest can be expanded. Sections Jikan had nothing for are left out
+func newSections(anime types.AnimeData) []section {
+	titles := []string{}
+	if anime.TitleEnglish != "" && anime.TitleEnglish != anime.Title {
+		titles = append(titles, "English: "+anime.TitleEnglish)
+	}
+	if anime.TitleJapanese != "" {
+		titles = append(titles, "Japanese: "+anime.TitleJapanese)
+	}
+	if len(anime.TitleSynonyms) > 0 {
+		titles = append(titles, "Also known as: "+strings.Join(anime.TitleSynonyms, ", "))
+	}
+
+	all := []section{
+		{title: "Synopsis", body: anime.Synopsis},
+		{title: "Background", body: anime.Background, collapsed: true},
+		{title: "Other titles", body: strings.Join(titles, "\n"), collapsed: true},
+		{title: "Trailer", body: anime.Trailer.URL, collapsed: true},
+	}
+
+	shown := []section{}
+	for _, s := range all {
+		if strings.TrimSpace(s.body) != "" {
+			shown = append(shown, s)
+		}
+	}
+	return shown
+}
+
+// Keeps which sections were collapsed when the same anime is loaded again, e.g. after a background refresh
+func keepCollapsed(old, updated []section) []section {
+	for i := range updated {
+		for _, o := range old {
+			if o.title == updated[i].title {
+				updated[i].collapsed = o.collapsed
+			}
+		}
+	}
+	return updated
+}
+
+// Renders the sections wrapped to width, along with the line each section's heading starts on
+func renderSections(sections []section, focused int, width int) (string, []int) {
+	lines := []string{}
+	offsets := []int{}
+	wrap := lipgloss.NewStyle().Width(width)
+
+	for i, s := range sections {
+		offsets = append(offsets, len(lines))
+
+		arrow := "▾ "
+		if s.collapsed {
+			arrow = "▸ "
+		}
+		style := sectionStyle
+		if i == focused {
+			style = focusedSectionStyle
+		}
+		lines = append(lines, style.Render(arrow+s.title))
+
+		if !s.collapsed {
+			lines = append(lines, strings.Split(wrap.Render(s.body), "\n")...)
+		}
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n"), offsets
+}
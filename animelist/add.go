@@ -0,0 +1,78 @@
+package animelist
+
+import (
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/editprompt"
+	"github.com/saubuny/haru/types"
+)
+
+// Completion of every anime in the DB, used to mark search results that are already in the list
+type listedMessage map[int64]string
+
+func (m Model) loadListed() tea.Msg {
+	anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
+	if err != nil {
+		return types.ErrorMsg(err.Error())
+	}
+
+	listed := listedMessage{}
+	for _, a := range anime {
+		listed[a.ID] = a.Completion
+	}
+	return listed
+}
+
+// Sent once a search result has been added to the list
+type addedMessage struct {
+	title      string
+	completion string
+}
+
+// The search result under the cursor, if there is one
+func (m Model) selectedSearchAnime() (types.AnimeData, bool) {
+	if m.showDBList || len(m.searchResults) == 0 {
+		return types.AnimeData{}, false
+	}
+	cursor := m.animeTable.Cursor()
+	if cursor < 0 || cursor >= len(m.searchResults) {
+		return types.AnimeData{}, false
+	}
+	return m.searchResults[cursor], true
+}
+
+// Adding is done in two steps: pick a completion, then enter a start date
+func (m *Model) startAdd() {
+	anime, ok := m.selectedSearchAnime()
+	if !ok {
+		return
+	}
+
+	m.adding = &anime
+	completion.Select(&m.completionSelector, m.listed[int64(anime.MalID)])
+
+	m.showCompletion = true
+	m.animeTable.Blur()
+}
+
+func (m *Model) startAddDate(completion string) {
+	m.addCompletion = completion
+	date := dates.Unknown
+	if completion != types.PlanToWatch {
+		date = "today"
+	}
+	m.edit.Start(editprompt.AddStartDate, date)
+	m.animeTable.Blur()
+}
+
+func (m Model) addCmd(anime types.AnimeData, completion string, startDate sql.NullString) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.dbConfig.AddAnime(int64(anime.MalID), anime.Title, completion, startDate); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return addedMessage{title: anime.Title, completion: completion}
+	}
+}
@@ -0,0 +1,36 @@
+package animelist
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/internal/database"
+)
+
+// Opens the completion popup for the selected anime, starting on its current completion
+func (m *Model) openCompletionSelector() {
+	anime, ok := m.selectedDBAnime()
+	if !ok {
+		return
+	}
+
+	completion.Select(&m.completionSelector, anime.Completion)
+
+	m.editID = anime.ID
+	m.showCompletion = true
+	m.animeTable.Blur()
+}
+
+func (m *Model) closeCompletionSelector() {
+	m.showCompletion = false
+	m.animeTable.Focus()
+}
+
+func (m Model) setCompletionCmd(id int64, completion string) tea.Cmd {
+	return m.updateCmd(func(updated string) error {
+		return m.dbConfig.DB.UpdateAnimeCompletion(m.dbConfig.Ctx, database.UpdateAnimeCompletionParams{
+			Completion:  completion,
+			Updateddate: updated,
+			ID:          id,
+		})
+	})
+}
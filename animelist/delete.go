@@ -0,0 +1,94 @@
+package animelist
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// How many deletes can be undone
+const maxUndo = 20
+
+// Sent once an anime has been deleted, so it can go in the undo buffer
+type deletedMessage database.Anime
+
+// Sent once the last deleted anime has been put back
+type restoredMessage database.Anime
+
+// Sent when putting an anime back failed, so it can go back in the undo buffer to try again
+type restoreFailedMessage struct {
+	anime database.Anime
+	err   error
+}
+
+func (m *Model) openDeleteConfirm() {
+	anime, ok := m.selectedDBAnime()
+	if !ok {
+		return
+	}
+
+	m.confirmDelete = &anime
+	m.animeTable.Blur()
+}
+
+func (m *Model) closeDeleteConfirm() {
+	m.confirmDelete = nil
+	m.animeTable.Focus()
+}
+
+func (m Model) deleteCmd(anime database.Anime) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.dbConfig.DB.DeleteAnime(m.dbConfig.Ctx, anime.ID); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return deletedMessage(anime)
+	}
+}
+
+// Puts the anime back exactly as it was, including the date it was last updated
+func (m Model) restoreCmd(anime database.Anime) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.dbConfig.DB.CreateAnime(m.dbConfig.Ctx, database.CreateAnimeParams{
+			ID:              anime.ID,
+			Title:           anime.Title,
+			Startdate:       anime.Startdate,
+			Updateddate:     anime.Updateddate,
+			Completion:      anime.Completion,
+			Watchedepisodes: anime.Watchedepisodes,
+			Score:           anime.Score,
+			Finishdate:      anime.Finishdate,
+			Timeswatched:    anime.Timeswatched,
+		})
+		if err != nil {
+			return restoreFailedMessage{anime: anime, err: err}
+		}
+		return restoredMessage(anime)
+	}
+}
+
+func (m *Model) pushUndo(anime database.Anime) {
+	m.deleted = append(m.deleted, anime)
+	if len(m.deleted) > maxUndo {
+		m.deleted = m.deleted[len(m.deleted)-maxUndo:]
+	}
+}
+
+// Takes the most recent delete off the undo buffer
+func (m *Model) popUndo() (database.Anime, bool) {
+	if len(m.deleted) == 0 {
+		return database.Anime{}, false
+	}
+	anime := m.deleted[len(m.deleted)-1]
+	m.deleted = m.deleted[:len(m.deleted)-1]
+	return anime, true
+}
+
+func (m Model) deleteConfirmView() string {
+	question := fmt.Sprintf("Delete %s?", lipgloss.NewStyle().Bold(true).Render(m.confirmDelete.Title))
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("y/enter to delete, n/esc to cancel, u to undo later")
+	return completion.PopupStyle.Render(lipgloss.JoinVertical(lipgloss.Center, question, "", hint))
+}
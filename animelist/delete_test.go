@@ -0,0 +1,67 @@
+package animelist
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/internal/database"
+)
+
+func TestUndo(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := InitialModel(db.DBConfig{}, nil)
+
+	if _, ok := m.popUndo(); ok {
+		t.Fatal("expected nothing to undo yet")
+	}
+
+	m.pushUndo(database.Anime{ID: 1})
+	m.pushUndo(database.Anime{ID: 2})
+	if anime, ok := m.popUndo(); !ok || anime.ID != 2 {
+		t.Fatalf("expected the latest delete to be undone first, got %d", anime.ID)
+	}
+	if anime, ok := m.popUndo(); !ok || anime.ID != 1 {
+		t.Fatalf("expected the earlier delete next, got %d", anime.ID)
+	}
+	if _, ok := m.popUndo(); ok {
+		t.Fatal("expected the undo buffer to be empty")
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := InitialModel(db.DBConfig{}, nil)
+
+	for id := range maxUndo + 5 {
+		m.pushUndo(database.Anime{ID: int64(id)})
+	}
+	if len(m.deleted) != maxUndo {
+		t.Fatalf("expected %d deletes kept, got %d", maxUndo, len(m.deleted))
+	}
+
+	// The oldest ones are forgotten first
+	if m.deleted[0].ID != 5 {
+		t.Fatalf("expected the oldest kept delete to be 5, got %d", m.deleted[0].ID)
+	}
+	if anime, _ := m.popUndo(); anime.ID != maxUndo+4 {
+		t.Fatalf("expected the latest delete to be kept, got %d", anime.ID)
+	}
+}
+
+func TestUndoFailedRestore(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	m := InitialModel(db.DBConfig{}, nil)
+
+	m.pushUndo(database.Anime{ID: 1, Title: "Frieren"})
+	anime, _ := m.popUndo()
+
+	updated, cmd := m.Update(restoreFailedMessage{anime: anime, err: errors.New("database is locked")})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected the error to be shown")
+	}
+	if anime, ok := m.popUndo(); !ok || anime.ID != 1 {
+		t.Fatal("expected the anime to be back in the undo buffer")
+	}
+}
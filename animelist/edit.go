@@ -0,0 +1,125 @@
+package animelist
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/editprompt"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// The anime under the cursor in the DB tab, if there is one
+func (m Model) selectedDBAnime() (database.Anime, bool) {
+	if !m.showDBList || len(m.dbAnime) == 0 {
+		return database.Anime{}, false
+	}
+	cursor := m.animeTable.Cursor()
+	if cursor < 0 || cursor >= len(m.dbAnime) {
+		return database.Anime{}, false
+	}
+	return m.dbAnime[cursor], true
+}
+
+// Opens the edit prompt for the selected anime, prefilled with its current value
+func (m *Model) startEdit(field editprompt.Field) {
+	anime, ok := m.selectedDBAnime()
+	if !ok {
+		return
+	}
+
+	m.editID = anime.ID
+	m.edit.Start(field, editprompt.Value(field, anime))
+	m.animeTable.Blur()
+}
+
+func (m *Model) stopEdit() {
+	m.adding = nil
+	m.edit.Stop()
+	m.animeTable.Focus()
+}
+
+// Validates the prompt and returns a command saving it, or an error to show under the prompt
+func (m Model) submitEdit() (tea.Cmd, error) {
+	switch m.edit.Field {
+	case editprompt.Episodes, editprompt.TimesWatched:
+		n, err := m.edit.Number()
+		if err != nil {
+			return nil, err
+		}
+		if m.edit.Field == editprompt.Episodes {
+			return m.setEpisodesCmd(m.editID, n), nil
+		}
+		return m.updateCmd(func(updated string) error {
+			return m.dbConfig.DB.UpdateAnimeTimesWatched(m.dbConfig.Ctx, database.UpdateAnimeTimesWatchedParams{
+				Timeswatched: n,
+				Updateddate:  updated,
+				ID:           m.editID,
+			})
+		}), nil
+	case editprompt.Score:
+		n, err := m.edit.Number()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(updated string) error {
+			return m.dbConfig.DB.UpdateAnimeScore(m.dbConfig.Ctx, database.UpdateAnimeScoreParams{
+				Score:       n,
+				Updateddate: updated,
+				ID:          m.editID,
+			})
+		}), nil
+	case editprompt.AddStartDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.addCmd(*m.adding, m.addCompletion, date), nil
+	case editprompt.StartDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(updated string) error {
+			return m.dbConfig.DB.UpdateAnimeStartDate(m.dbConfig.Ctx, database.UpdateAnimeStartDateParams{
+				Startdate:   date,
+				Updateddate: updated,
+				ID:          m.editID,
+			})
+		}), nil
+	case editprompt.FinishDate:
+		date, err := m.edit.Date()
+		if err != nil {
+			return nil, err
+		}
+		return m.updateCmd(func(updated string) error {
+			return m.dbConfig.DB.UpdateAnimeFinishDate(m.dbConfig.Ctx, database.UpdateAnimeFinishDateParams{
+				Finishdate:  date,
+				Updateddate: updated,
+				ID:          m.editID,
+			})
+		}), nil
+	}
+
+	return nil, nil
+}
+
+func (m Model) setEpisodesCmd(id int64, episodes int64) tea.Cmd {
+	return m.updateCmd(func(updated string) error {
+		return m.dbConfig.DB.UpdateAnimeEpisodes(m.dbConfig.Ctx, database.UpdateAnimeEpisodesParams{
+			Watchedepisodes: max(0, episodes),
+			Updateddate:     updated,
+			ID:              id,
+		})
+	})
+}
+
+// Runs an update with today's date, then reloads the DB tab in place
+func (m Model) updateCmd(update func(updated string) error) tea.Cmd {
+	return func() tea.Msg {
+		if err := update(time.Now().Format("2006-01-02")); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return m.refreshDB()
+	}
+}
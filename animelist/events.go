@@ -7,3 +7,6 @@ import (
 
 type AnimeListMessage types.AnimeListResponse
 type AnimeDBListMessage []database.Anime
+
+// Same as AnimeDBListMessage, but keeps the cursor where it was
+type AnimeDBRefreshMessage []database.Anime
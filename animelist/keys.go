@@ -11,6 +11,23 @@ type KeyMap struct {
 	Esc    key.Binding
 	Help   key.Binding
 	Tab    key.Binding
+
+	Add               key.Binding
+	Filters           key.Binding
+	Seasonal          key.Binding
+	Calendar          key.Binding
+	NextPage          key.Binding
+	PrevPage          key.Binding
+	Delete            key.Binding
+	Undo              key.Binding
+	Completion        key.Binding
+	IncrementEpisodes key.Binding
+	DecrementEpisodes key.Binding
+	EditEpisodes      key.Binding
+	EditScore         key.Binding
+	EditStartDate     key.Binding
+	EditFinishDate    key.Binding
+	EditTimesWatched  key.Binding
 }
 
 // ShortHelp implements the KeyMap interface.
@@ -22,7 +39,12 @@ func (km KeyMap) ShortHelp() []key.Binding {
 func (km KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{km.Up, km.Down, km.Esc, km.Tab},
-		{km.Select, km.Help},
+		{km.Select, km.Help, km.Add, km.Filters},
+		{km.Seasonal, km.Calendar},
+		{km.NextPage, km.PrevPage},
+		{km.Delete, km.Undo},
+		{km.Completion, km.IncrementEpisodes, km.DecrementEpisodes, km.EditEpisodes},
+		{km.EditScore, km.EditStartDate, km.EditFinishDate, km.EditTimesWatched},
 	}
 }
 
@@ -51,4 +73,68 @@ var AnimeListKeyMap = KeyMap{
 		key.WithKeys("tab"),
 		key.WithHelp("tab", "change tab"),
 	),
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add to list"),
+	),
+	Filters: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "search filters"),
+	),
+	Seasonal: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "seasonal anime"),
+	),
+	Calendar: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "airing calendar"),
+	),
+	NextPage: key.NewBinding(
+		key.WithKeys("n", "]"),
+		key.WithHelp("n", "next page"),
+	),
+	PrevPage: key.NewBinding(
+		key.WithKeys("p", "["),
+		key.WithHelp("p", "previous page"),
+	),
+	Delete: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "delete"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo delete"),
+	),
+	Completion: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "set completion"),
+	),
+	IncrementEpisodes: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "watched episode"),
+	),
+	DecrementEpisodes: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "unwatch episode"),
+	),
+	EditEpisodes: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "set episodes"),
+	),
+	EditScore: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rate"),
+	),
+	EditStartDate: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "set start date"),
+	),
+	EditFinishDate: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "set finish date"),
+	),
+	EditTimesWatched: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "set rewatches"),
+	),
 }
@@ -1,15 +1,14 @@
 package animelist
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
 	"strconv"
 	"strings"
 	"time"
-	"unicode"
-
-	"encoding/json"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -20,33 +19,72 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/saubuny/haru/animeinfo"
+	"github.com/saubuny/haru/calendar"
+	"github.com/saubuny/haru/completion"
+	"github.com/saubuny/haru/dates"
 	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/editprompt"
+	"github.com/saubuny/haru/filters"
+	"github.com/saubuny/haru/jikan"
 	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/overlay"
+	"github.com/saubuny/haru/seasonal"
 	"github.com/saubuny/haru/types"
 
 	"github.com/saubuny/haru/internal/database"
 )
 
-// Completion selector will be on this page. pressing c will open the popup and pressing s will open the start date entry. you can press d to delete an entry (a confirmation popup will show). the selector will be pushed to the
+// The completion selector (c) and delete confirmation (d) are popups drawn over the table with the overlay package. Every other field (s, f, e, r, w) is edited in a prompt that replaces the search bar
 
 var baseStyle = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240"))
 
+var filtersStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+// Most cached results shown for a search while offline
+const offlineResults = 25
+
 type Model struct {
 	width  int
 	height int
 
-	showHelp    bool
-	showDBList  bool
-	showSpinner bool
+	showHelp       bool
+	showDBList     bool
+	showSpinner    bool
+	showCompletion bool
 
 	dbConfig           db.DBConfig
+	jikan              *jikan.Client
 	animeTable         table.Model
 	searchInput        textinput.Model
 	help               help.Model
 	completionSelector list.Model
+
+	// Rows currently shown in the DB tab, and the search that produced them
+	dbAnime  []database.Anime
+	dbFilter string
+
+	// Search results shown in the other tab, and the completion of everything already in the list
+	searchResults []types.AnimeData
+	listed        map[int64]string
+
+	// Filters for searching in the other tab, the last search made with them, and how many pages of results have loaded
+	searchFilters jikan.SearchFilters
+	lastSearch    string
+	pages         pagination
+
+	// Search result being added to the list
+	adding        *types.AnimeData
+	addCompletion string
+
+	// Anime waiting on the delete confirmation, and previously deleted anime that can be restored
+	confirmDelete *database.Anime
+	deleted       []database.Anime
+
+	edit   editprompt.Model
+	editID int64
 }
 
-func InitialModel(db db.DBConfig) Model {
+func InitialModel(db db.DBConfig, client *jikan.Client) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Insert Peak Here..."
 	ti.Blur()
@@ -75,171 +113,438 @@ func InitialModel(db db.DBConfig) Model {
 	help := help.New()
 	help.ShowAll = true
 
-	// items := []list.Item{}
-	// sel := list.New(items, list.DefaultDelegate{}, 0, len(items))
+	searchFilters, err := filters.Load()
+	if err != nil {
+		log.Printf("Could not load the last search filters: %v", err)
+	}
 
 	return Model{
-		animeTable:  tb,
-		help:        help,
-		searchInput: ti,
-		dbConfig:    db,
-		showHelp:    true,
-		showDBList:  true,
+		searchFilters:      searchFilters,
+		animeTable:         tb,
+		completionSelector: completion.NewSelector(),
+		help:               help,
+		searchInput:        ti,
+		edit:               editprompt.New(),
+		dbConfig:           db,
+		jikan:              client,
+		showHelp:           true,
+		showDBList:         true,
 	}
 }
 
-func getAnimeById(id string) (types.AnimeDataResponse, error) {
-	c := &http.Client{Timeout: 4 * time.Second}
-	res, err := c.Get("https://api.jikan.moe/v4/anime/" + id)
-	if err != nil {
-		return types.AnimeDataResponse{}, err
-	}
+// Shows cached details straight away, refreshing them in the background if they're stale. Anything not cached yet gets fetched
+func (m Model) loadAnimeCmd(malID int) tea.Cmd {
+	return func() tea.Msg {
+		cached, _, err := m.dbConfig.CachedAnime(int64(malID))
+		switch {
+		case err == sql.ErrNoRows:
+			return navstack.Retryable(m.fetchAnimeCmd(malID))()
+		case err != nil:
+			return types.ErrorMsg(err.Error())
+		}
 
-	var anime types.AnimeDataResponse
-	err = json.NewDecoder(res.Body).Decode(&anime)
-	if err != nil {
-		return types.AnimeDataResponse{}, nil
+		return types.AnimeDataMessage{Data: cached}
 	}
-
-	return anime, nil
 }
 
-func getAnimeByIdCmd(id string) tea.Cmd {
+func (m Model) fetchAnimeCmd(id int) tea.Cmd {
 	return func() tea.Msg {
-		anime, err := getAnimeById(id)
+		anime, err := m.jikan.GetAnime(context.Background(), id)
+		if errors.Is(err, jikan.ErrOffline) {
+			return types.ErrorMsg("offline, and this anime hasn't been cached yet")
+		}
 		if err != nil {
 			return types.ErrorMsg(err.Error())
 		}
+
+		m.cacheAnime(anime.Data)
 		return types.AnimeDataMessage(anime)
 	}
 }
 
-func searchAnimeByNameCmd(searchString string) tea.Cmd {
-	// Breaks from spaces, have to replace them
-	newSearchString := ""
-	for _, r := range searchString {
-		if unicode.IsSpace(r) {
-			newSearchString += "%20"
-		} else {
-			newSearchString += string(r)
+// Fetches the anime again if its cached details are out of date. Meant to run after loadAnimeCmd, so failing is fine since the cached details are
+// already showing
+func (m Model) refreshAnimeCmd(id int) tea.Cmd {
+	return func() tea.Msg {
+		_, fetched, err := m.dbConfig.CachedAnime(int64(id))
+		if err != nil || time.Since(fetched) <= db.DefaultCacheTTL {
+			return nil
 		}
-	}
 
-	return func() tea.Msg {
-		c := &http.Client{Timeout: 4 * time.Second}
-		res, err := c.Get("https://api.jikan.moe/v4/anime?q=" + newSearchString)
+		anime, err := m.jikan.GetAnime(context.Background(), id)
 		if err != nil {
-			return types.ErrorMsg(err.Error())
+			if !errors.Is(err, jikan.ErrOffline) {
+				log.Printf("Could not refresh cached anime %d: %v", id, err)
+			}
+			return nil
+		}
+
+		m.cacheAnime(anime.Data)
+		return animeinfo.RefreshedMessage{ID: int64(id), Anime: anime.Data}
+	}
+}
+
+// A broken cache shouldn't stop anything from showing, so errors are only logged
+func (m Model) cacheAnime(anime ...types.AnimeData) {
+	for _, a := range anime {
+		if err := m.dbConfig.CacheAnime(a); err != nil {
+			log.Printf("Could not cache anime %d: %v", a.MalID, err)
 		}
+	}
+}
 
-		var anime types.AnimeListResponse
-		err = json.NewDecoder(res.Body).Decode(&anime)
+// A page of whatever the search tab is showing: the last search, everything matching the filters if nothing was searched, or else the top anime
+func (m Model) fetchResults(page int) (types.AnimeListResponse, error) {
+	if m.lastSearch == "" && !m.searchFilters.Active() {
+		return m.jikan.TopAnime(context.Background(), page)
+	}
+	return m.jikan.SearchAnime(context.Background(), m.lastSearch, m.searchFilters, page)
+}
+
+// Replaces the search tab's results with the first page. Set lastSearch before calling.
+// The filters aren't used for cached results while offline, since most of what they filter on isn't cached
+func (m Model) resultsCmd() tea.Cmd {
+	return navstack.Retryable(func() tea.Msg {
+		anime, err := m.fetchResults(1)
+		if errors.Is(err, jikan.ErrOffline) {
+			if strings.TrimSpace(m.lastSearch) == "" {
+				return m.cachedResults(m.dbConfig.TopCachedAnime(offlineResults))
+			}
+			return m.cachedResults(m.dbConfig.SearchCachedAnime(m.lastSearch, offlineResults))
+		}
 		if err != nil {
 			return types.ErrorMsg(err.Error())
 		}
 
+		m.cacheAnime(anime.Data...)
 		return AnimeListMessage(anime)
-	}
+	})
 }
 
-func (m *Model) getTopAnime() tea.Msg {
-	c := &http.Client{Timeout: 4 * time.Second}
-	res, err := c.Get("https://api.jikan.moe/v4/top/anime")
+// While offline, search and top anime come from whatever has been cached before
+func (m Model) cachedResults(anime []types.AnimeData, err error) tea.Msg {
 	if err != nil {
 		return types.ErrorMsg(err.Error())
 	}
 
-	var topAnime types.AnimeListResponse
-	err = json.NewDecoder(res.Body).Decode(&topAnime)
+	return AnimeListMessage(types.AnimeListResponse{Data: anime})
+}
+
+func (m Model) showDBAnime() tea.Msg {
+	anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
 	if err != nil {
 		return types.ErrorMsg(err.Error())
 	}
 
-	return AnimeListMessage(topAnime)
+	return AnimeDBListMessage(anime)
 }
 
-func (m Model) showDBAnime() tea.Msg {
-	anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
+// Reloads the DB tab with the current search, without moving the cursor
+func (m Model) refreshDB() tea.Msg {
+	anime, err := m.filterDB(m.dbFilter)
 	if err != nil {
 		return types.ErrorMsg(err.Error())
 	}
 
-	return AnimeDBListMessage(anime)
+	return AnimeDBRefreshMessage(anime)
 }
 
-func (m Model) searchDBByNameCmd(searchString string) tea.Cmd {
-	return func() tea.Msg {
-		fullAnime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
-		if err != nil {
-			return types.ErrorMsg(err.Error())
-		}
+func dbColumns() []table.Column {
+	return []table.Column{
+		{Title: "Id", Width: 8},
+		{Title: "Name", Width: 36},
+		{Title: "Completion", Width: 14},
+		{Title: "Eps", Width: 5},
+		{Title: "Score", Width: 5},
+		{Title: "Start Date", Width: 10},
+		{Title: "Finish Date", Width: 11},
+		{Title: "Rewatches", Width: 9},
+	}
+}
 
-		// I know nothing about search algorithms, so i'm doing this simple thing for now. maybe i can do something better in the future, idk
-		newAnime := []database.Anime{}
-		for _, anime := range fullAnime {
-			if strings.Contains(strings.ToLower(anime.Title), strings.ToLower(searchString)) {
-				newAnime = append(newAnime, anime)
-			}
+func dbRow(anime database.Anime, query string) table.Row {
+	score := "-"
+	if anime.Score > 0 {
+		score = strconv.Itoa(int(anime.Score))
+	}
+
+	return table.Row{
+		strconv.Itoa(int(anime.ID)),
+		highlightTitle(anime.Title, query, dbColumns()[1].Width),
+		anime.Completion,
+		strconv.Itoa(int(anime.Watchedepisodes)),
+		score,
+		dates.Display(anime.Startdate),
+		dates.Display(anime.Finishdate),
+		strconv.Itoa(int(anime.Timeswatched)),
+	}
+}
+
+func (m *Model) setSearchRows() {
+	columns := []table.Column{
+		{Title: "Id", Width: 10},
+		{Title: "Name", Width: 40},
+		{Title: "Rating", Width: 30},
+		{Title: "Score", Width: 10},
+		{Title: "In List", Width: 14},
+	}
+
+	rows := make([]table.Row, 0)
+	for _, anime := range m.searchResults {
+		inList := ""
+		if completion, ok := m.listed[int64(anime.MalID)]; ok {
+			inList = "✓ " + completion
 		}
+		rows = append(rows, table.Row{strconv.Itoa(anime.MalID), anime.Title, anime.Rating, fmt.Sprintf("%v", anime.Score), inList})
+	}
+
+	m.animeTable.SetRows(nil)
+	m.animeTable.SetColumns(columns)
+	m.animeTable.SetRows(rows)
+}
+
+// Fills the table with DB rows. Clearing the rows first stops a crash when the other tab had fewer columns
+func (m *Model) setDBRows(anime []database.Anime) {
+	rows := make([]table.Row, 0)
+	for _, a := range anime {
+		rows = append(rows, dbRow(a, m.dbFilter))
+	}
+
+	m.dbAnime = anime
+	m.animeTable.SetRows(nil)
+	m.animeTable.SetColumns(dbColumns())
+	m.animeTable.SetRows(rows)
+}
+
+// The active filters are shown above the search results
+func (m Model) showFilters() bool {
+	return !m.showDBList && m.searchFilters.Active()
+}
 
-		return AnimeDBListMessage(newAnime)
+func (m *Model) resizeTable() {
+	// Height of help + search bar
+	height := m.height - 10
+	if m.showFilters() {
+		height--
 	}
+	// The page indicator under the search results
+	if !m.showDBList {
+		height--
+	}
+	m.animeTable.SetHeight(height)
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.showDBAnime
+	return tea.Batch(m.showDBAnime, m.retryPendingCmd)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case types.ErrorMsg:
-		log.Fatalf("Error: %v", msg)
+		// Shown in the status bar by navstack
+		m.showSpinner = false
+		m.pages.loading = false
+		m.pages.jumpTo = 0
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 
-		// Height of help + search bar
-		m.animeTable.SetHeight(m.height - 10)
+		m.resizeTable()
 		m.searchInput.Width = int(float64(m.width)*0.8) / 3
+		m.edit.Input.Width = m.searchInput.Width
 		return m, nil
 	case AnimeDBListMessage:
-		columns := []table.Column{
-			{Title: "Id", Width: 10},
-			{Title: "Name", Width: 40},
-			{Title: "Completion", Width: 20},
-			{Title: "Start Date", Width: 10},
-		}
-
-		rows := make([]table.Row, 0)
-		for _, anime := range msg {
-			rows = append(rows, table.Row{strconv.Itoa(int(anime.ID)), anime.Title, anime.Completion, anime.Startdate})
+		m.setDBRows(msg)
+		m.animeTable.SetCursor(0)
+		return m, nil
+	case dbSearchMessage:
+		if msg.query != m.dbFilter || !m.showDBList {
+			return m, nil
 		}
-
-		m.animeTable.SetColumns(columns)
-		m.animeTable.SetRows(rows)
+		m.setDBRows(msg.anime)
 		m.animeTable.SetCursor(0)
 		return m, nil
+	case AnimeDBRefreshMessage:
+		cursor := m.animeTable.Cursor()
+		m.setDBRows(msg)
+		m.animeTable.SetCursor(min(cursor, len(msg)-1))
+		return m, nil
 	case AnimeListMessage:
-		// There is a crash if the number of columns differs between both tabs
-		columns := []table.Column{
-			{Title: "Id", Width: 10},
-			{Title: "Name", Width: 40},
-			{Title: "Rating", Width: 30},
-			{Title: "Score", Width: 10},
-		}
-
-		rows := make([]table.Row, 0)
-		for _, anime := range msg.Data {
-			rows = append(rows, table.Row{strconv.Itoa(anime.MalID), anime.Title, anime.Rating, fmt.Sprintf("%v", anime.Score)})
-		}
-
-		m.animeTable.SetColumns(columns)
-		m.animeTable.SetRows(rows)
+		m.searchResults = msg.Data
+		m.setSearchRows()
 		m.animeTable.SetCursor(0)
 		m.showSpinner = false
+		m.pages.reset(types.AnimeListResponse(msg))
+		return m, nil
+	case resultsPageMessage:
+		m.addPage(msg)
+		return m, nil
+	case deletedMessage:
+		m.pushUndo(database.Anime(msg))
+		return m, tea.Batch(m.refreshDB, navstack.Cmd(navstack.Notify(fmt.Sprintf("Deleted %s, press u to undo", msg.Title))))
+	case restoredMessage:
+		return m, tea.Batch(m.refreshDB, navstack.Cmd(navstack.Notify(fmt.Sprintf("Restored %s", msg.Title))))
+	case restoreFailedMessage:
+		m.pushUndo(msg.anime)
+		return m, navstack.Cmd(types.ErrorMsg(fmt.Sprintf("Could not restore %s: %v", msg.anime.Title, msg.err)))
+	case addedMessage:
+		return m, tea.Batch(m.loadListed, navstack.Cmd(navstack.Notify(fmt.Sprintf("Added %s as %s", msg.title, msg.completion))))
+	case filters.AppliedMessage:
+		m.searchFilters = jikan.SearchFilters(msg)
+		m.resizeTable()
+		return m, m.resultsCmd()
+	case navstack.ResumeNavigation:
+		// Only the top screen gets messages, so a page that arrived while the info page was open was dropped. If it's still on its way, it gets
+		// added as long as it's still the next page
+		m.pages.loading = false
+		m.pages.jumpTo = 0
+
+		// The info page can change tracking, so what's shown might be out of date
+		if m.showDBList {
+			return m, m.refreshDB
+		}
+		return m, m.loadListed
+	case pendingMessage:
+		for _, entry := range msg.unresolved {
+			log.Printf("Could not find a confident MAL match for %s, add it manually", entry)
+		}
+		notice := fmt.Sprintf("Added %d anime that were queued while offline", msg.added)
+		if len(msg.unresolved) > 0 {
+			notice += fmt.Sprintf(", %d couldn't be matched (see the log)", len(msg.unresolved))
+		}
+		cmds := []tea.Cmd{navstack.Cmd(navstack.Notify(notice))}
+		if m.showDBList {
+			cmds = append(cmds, m.refreshDB)
+		}
+		return m, tea.Batch(cmds...)
+	case listedMessage:
+		m.listed = msg
+		if !m.showDBList {
+			cursor := m.animeTable.Cursor()
+			m.setSearchRows()
+			m.animeTable.SetCursor(cursor)
+		}
 		return m, nil
 	case tea.KeyMsg:
+		if m.confirmDelete != nil {
+			switch msg.String() {
+			case "y", "enter":
+				anime := *m.confirmDelete
+				m.closeDeleteConfirm()
+				return m, m.deleteCmd(anime)
+			case "n", "esc":
+				m.closeDeleteConfirm()
+			}
+			return m, nil
+		}
+
+		if m.showCompletion {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.closeCompletionSelector()
+				m.adding = nil
+				return m, nil
+			case tea.KeyEnter:
+				m.closeCompletionSelector()
+				item, ok := m.completionSelector.SelectedItem().(completion.Item)
+				if !ok {
+					m.adding = nil
+					return m, nil
+				}
+				if m.adding != nil {
+					m.startAddDate(string(item))
+					return m, nil
+				}
+				return m, m.setCompletionCmd(m.editID, string(item))
+			}
+			m.completionSelector, cmd = m.completionSelector.Update(msg)
+			return m, cmd
+		}
+
+		if m.edit.Editing() {
+			switch msg.Type {
+			case tea.KeyEsc:
+				m.stopEdit()
+				return m, nil
+			case tea.KeyEnter:
+				save, err := m.submitEdit()
+				if err != nil {
+					m.edit.Err = err.Error()
+					return m, nil
+				}
+				m.stopEdit()
+				return m, save
+			}
+			m.edit, cmd = m.edit.Update(msg)
+			return m, cmd
+		}
+
+		if m.showDBList && m.animeTable.Focused() {
+			switch {
+			case key.Matches(msg, AnimeListKeyMap.Completion):
+				m.openCompletionSelector()
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.Delete):
+				m.openDeleteConfirm()
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.Undo):
+				if anime, ok := m.popUndo(); ok {
+					return m, m.restoreCmd(anime)
+				}
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.IncrementEpisodes):
+				if anime, ok := m.selectedDBAnime(); ok {
+					return m, m.setEpisodesCmd(anime.ID, anime.Watchedepisodes+1)
+				}
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.DecrementEpisodes):
+				if anime, ok := m.selectedDBAnime(); ok {
+					return m, m.setEpisodesCmd(anime.ID, anime.Watchedepisodes-1)
+				}
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.EditEpisodes):
+				m.startEdit(editprompt.Episodes)
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.EditScore):
+				m.startEdit(editprompt.Score)
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.EditStartDate):
+				m.startEdit(editprompt.StartDate)
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.EditFinishDate):
+				m.startEdit(editprompt.FinishDate)
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.EditTimesWatched):
+				m.startEdit(editprompt.TimesWatched)
+				return m, nil
+			}
+		}
+
+		if m.animeTable.Focused() {
+			switch {
+			case key.Matches(msg, AnimeListKeyMap.Seasonal):
+				return m, navstack.Cmd(navstack.PushNavigation{Item: seasonal.New(m.dbConfig, m.jikan)})
+			case key.Matches(msg, AnimeListKeyMap.Calendar):
+				return m, navstack.Cmd(navstack.PushNavigation{Item: calendar.New(m.dbConfig, m.jikan)})
+			}
+		}
+
+		if !m.showDBList && m.animeTable.Focused() {
+			switch {
+			case key.Matches(msg, AnimeListKeyMap.Add):
+				m.startAdd()
+				return m, nil
+			case key.Matches(msg, AnimeListKeyMap.Filters):
+				return m, navstack.Cmd(navstack.PushNavigation{Item: filters.New(m.searchFilters)})
+			case key.Matches(msg, AnimeListKeyMap.NextPage):
+				return m, m.goToPage(m.cursorPage() + 1)
+			case key.Matches(msg, AnimeListKeyMap.PrevPage):
+				return m, m.goToPage(m.cursorPage() - 1)
+			}
+		}
+
 		switch {
 		case key.Matches(msg, AnimeListKeyMap.Help):
 			m.showHelp = !m.showHelp
@@ -255,33 +560,60 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case !m.showSpinner && m.animeTable.Focused() && key.Matches(msg, AnimeListKeyMap.Tab):
 			m.showDBList = !m.showDBList
+			m.searchInput.Reset()
+			m.resizeTable()
 			if !m.showDBList {
-				return m, m.getTopAnime
+				m.lastSearch = ""
+				return m, tea.Batch(m.resultsCmd(), m.loadListed)
 			}
+			m.dbFilter = ""
 			return m, m.showDBAnime
 		case key.Matches(msg, AnimeListKeyMap.Select):
 			if m.searchInput.Focused() {
-				val := m.searchInput.Value()
-				m.searchInput.Reset()
 				m.animeTable.Focus()
 				m.searchInput.Blur()
+
+				// The DB tab has already been searching while typing, so the search is left in place to show what's filtered
 				if m.showDBList {
-					return m, m.searchDBByNameCmd(val)
+					return m, nil
 				}
-				return m, searchAnimeByNameCmd(val)
+
+				m.lastSearch = m.searchInput.Value()
+				m.searchInput.Reset()
+				return m, m.resultsCmd()
 			}
 
+			if m.animeTable.SelectedRow() == nil {
+				return m, nil
+			}
+
+			id, err := strconv.Atoi(m.animeTable.SelectedRow()[0])
+			if err != nil {
+				return m, nil
+			}
+			// In order, so the refresh can't arrive before the cached details it replaces
 			return m, tea.Sequence(
 				navstack.Cmd(navstack.PushNavigation{
-					Item: animeinfo.New(),
+					Item: animeinfo.New(m.dbConfig, int64(id)),
 				}),
-				getAnimeByIdCmd(m.animeTable.SelectedRow()[0]),
+				m.loadAnimeCmd(id),
+				m.refreshAnimeCmd(id),
 			)
 		}
 	}
 
 	m.animeTable, cmd = m.animeTable.Update(msg)
 	m.searchInput, cmd = m.searchInput.Update(msg)
+
+	if m.showDBList && m.searchInput.Value() != m.dbFilter {
+		m.dbFilter = m.searchInput.Value()
+		return m, tea.Batch(cmd, m.searchDBByNameCmd(m.dbFilter))
+	}
+
+	// Reaching the last row loads the next page onto the end
+	if !m.showDBList && m.animeTable.Focused() && len(m.searchResults) > 0 && m.animeTable.Cursor() == len(m.searchResults)-1 {
+		return m, tea.Batch(cmd, m.loadMore())
+	}
 	return m, cmd
 }
 
@@ -291,12 +623,31 @@ func (m Model) View() string {
 	}
 	render := ""
 
-	render += baseStyle.Render(m.searchInput.View()) + "\n"
+	if m.edit.Editing() {
+		render += baseStyle.Render(m.edit.View()) + "\n"
+	} else {
+		render += baseStyle.Render(m.searchInput.View()) + "\n"
+	}
+	if m.showFilters() {
+		line := "Filters: " + strings.Join(filters.Describe(m.searchFilters), " · ") + "  (F to change)"
+		render += filtersStyle.MaxWidth(int(float64(m.width)*0.8)).Render(line) + "\n"
+	}
 	render += baseStyle.Render(m.animeTable.View()) + "\n"
+	if !m.showDBList {
+		render += filtersStyle.Render(m.pageView()) + "\n"
+	}
 
 	if m.showHelp {
 		render += m.help.View(AnimeListKeyMap)
 	}
 
-	return lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+	render = lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+	if m.showCompletion {
+		render = overlay.Center(completion.View(m.completionSelector), render)
+	}
+	if m.confirmDelete != nil {
+		render = overlay.Center(m.deleteConfirmView(), render)
+	}
+
+	return render
 }
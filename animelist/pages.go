@@ -0,0 +1,161 @@
+package animelist
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/types"
+)
+
+// Jikan gives search results and top anime a page at a time. Moving past the last row loads the next page onto the end of the table,
+// and n/p jump between pages, loading the next one first if it isn't there yet
+
+// Jikan's page size, used until a response says otherwise
+const defaultPerPage = 25
+
+// Another page of the results already showing. Dropped if it belongs to an older search
+type resultsPageMessage struct {
+	search  int
+	page    int
+	results types.AnimeListResponse
+}
+
+type pagination struct {
+	// Bumped every time the first page of new results arrives
+	search int
+
+	loaded   int
+	lastPage int // 0 if Jikan didn't say, e.g. for cached results
+	hasNext  bool
+	total    int
+	perPage  int
+
+	loading bool
+	// Page to move the cursor to once it has loaded, 0 for none
+	jumpTo int
+}
+
+// Starts over with the first page of new results
+func (p *pagination) reset(first types.AnimeListResponse) {
+	*p = pagination{search: p.search + 1}
+	p.loaded = 1
+	p.update(first)
+}
+
+func (p *pagination) update(res types.AnimeListResponse) {
+	p.lastPage = res.Pagination.LastVisiblePage
+	p.hasNext = res.Pagination.HasNextPage
+	p.total = res.Pagination.Items.Total
+	p.perPage = res.Pagination.Items.PerPage
+	if p.perPage == 0 {
+		p.perPage = defaultPerPage
+	}
+}
+
+// Which page the row at cursor came from
+func (p pagination) pageOf(cursor int) int {
+	perPage := p.perPage
+	if perPage == 0 {
+		perPage = defaultPerPage
+	}
+	return cursor/perPage + 1
+}
+
+func (m Model) cursorPage() int {
+	return m.pages.pageOf(max(0, m.animeTable.Cursor()))
+}
+
+func (m Model) loadPageCmd(page int) tea.Cmd {
+	search := m.pages.search
+	return navstack.Retryable(func() tea.Msg {
+		anime, err := m.fetchResults(page)
+		if errors.Is(err, jikan.ErrOffline) {
+			return types.ErrorMsg("offline, can't load any more results")
+		}
+		if err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+
+		m.cacheAnime(anime.Data...)
+		return resultsPageMessage{search: search, page: page, results: anime}
+	})
+}
+
+// Starts loading the page after the last one loaded, unless it's already loading or there isn't one
+func (m *Model) loadMore() tea.Cmd {
+	if m.pages.loading || !m.pages.hasNext {
+		return nil
+	}
+
+	m.pages.loading = true
+	return m.loadPageCmd(m.pages.loaded + 1)
+}
+
+// Moves the cursor to the first row of page, loading it first if it's the next one
+func (m *Model) goToPage(page int) tea.Cmd {
+	if page < 1 || len(m.searchResults) == 0 {
+		return nil
+	}
+
+	if page <= m.pages.loaded {
+		m.animeTable.SetCursor((page - 1) * m.pages.perPage)
+		return nil
+	}
+
+	if page == m.pages.loaded+1 && m.pages.hasNext {
+		m.pages.jumpTo = page
+		return m.loadMore()
+	}
+	return nil
+}
+
+// Adds a page onto the end of the results, as long as it's the one that comes next
+func (m *Model) addPage(msg resultsPageMessage) {
+	if msg.search != m.pages.search || msg.page != m.pages.loaded+1 {
+		return
+	}
+
+	m.pages.loading = false
+	m.pages.loaded = msg.page
+	m.pages.update(msg.results)
+
+	cursor := m.animeTable.Cursor()
+	m.searchResults = append(m.searchResults, msg.results.Data...)
+	m.setSearchRows()
+	m.animeTable.SetCursor(cursor)
+
+	if m.pages.jumpTo == msg.page {
+		m.pages.jumpTo = 0
+		m.animeTable.SetCursor(min((msg.page-1)*m.pages.perPage, len(m.searchResults)-1))
+	}
+}
+
+// e.g. "Page 2 of 40 · 1000 results", or just how many results there are if Jikan didn't say how many pages there are
+func (m Model) pageView() string {
+	if len(m.searchResults) == 0 {
+		return "No results"
+	}
+	if m.pages.lastPage == 0 {
+		return results(len(m.searchResults))
+	}
+
+	total := m.pages.total
+	if total == 0 {
+		total = len(m.searchResults)
+	}
+	view := fmt.Sprintf("Page %d of %d · %s", m.cursorPage(), m.pages.lastPage, results(total))
+	if m.pages.loading {
+		view += " · loading more..."
+	}
+	return view
+}
+
+func results(n int) string {
+	if n == 1 {
+		return "1 result"
+	}
+	return fmt.Sprintf("%d results", n)
+}
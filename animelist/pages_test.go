@@ -0,0 +1,103 @@
+package animelist
+
+import (
+	"testing"
+
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/types"
+)
+
+func resultsPage(from, count, lastPage int) types.AnimeListResponse {
+	res := types.AnimeListResponse{}
+	for i := range count {
+		res.Data = append(res.Data, types.AnimeData{MalID: from + i})
+	}
+	res.Pagination.LastVisiblePage = lastPage
+	res.Pagination.HasNextPage = from/25+1 < lastPage
+	res.Pagination.Items.Total = 60
+	res.Pagination.Items.PerPage = 25
+	return res
+}
+
+func TestPagination(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	m := InitialModel(db.DBConfig{}, nil)
+	m.showDBList = false
+	m.animeTable.SetHeight(10)
+
+	updated, _ := m.Update(AnimeListMessage(resultsPage(0, 25, 3)))
+	m = updated.(Model)
+	if view := m.pageView(); view != "Page 1 of 3 · 60 results" {
+		t.Fatalf("unexpected page indicator %q", view)
+	}
+
+	// Going past the first page needs it loading first
+	if cmd := m.goToPage(2); cmd == nil || !m.pages.loading {
+		t.Fatal("expected the next page to start loading")
+	}
+	if cmd := m.loadMore(); cmd != nil {
+		t.Fatal("expected only one page to load at a time")
+	}
+
+	// Pages from an older search are dropped
+	m.addPage(resultsPageMessage{search: m.pages.search - 1, page: 2, results: resultsPage(25, 25, 3)})
+	if len(m.searchResults) != 25 {
+		t.Fatalf("expected a page from an old search to be dropped, got %d results", len(m.searchResults))
+	}
+
+	m.addPage(resultsPageMessage{search: m.pages.search, page: 2, results: resultsPage(25, 25, 3)})
+	if len(m.searchResults) != 50 || m.pages.loading {
+		t.Fatalf("expected 50 results after the second page, got %d", len(m.searchResults))
+	}
+	if m.animeTable.Cursor() != 25 || m.cursorPage() != 2 {
+		t.Fatalf("expected the cursor on the first row of page 2, got row %d", m.animeTable.Cursor())
+	}
+
+	// Pages already loaded are jumped to straight away
+	if cmd := m.goToPage(1); cmd != nil || m.animeTable.Cursor() != 0 {
+		t.Fatalf("expected to jump back to the first row, got row %d", m.animeTable.Cursor())
+	}
+
+	m.addPage(resultsPageMessage{search: m.pages.search, page: 3, results: resultsPage(50, 10, 3)})
+	if m.pages.hasNext || m.loadMore() != nil {
+		t.Fatal("expected nothing left to load after the last page")
+	}
+
+	// Cached results don't have pages
+	updated, _ = m.Update(AnimeListMessage{Data: []types.AnimeData{{MalID: 1}}})
+	m = updated.(Model)
+	if view := m.pageView(); view != "1 result" {
+		t.Fatalf("unexpected indicator for cached results %q", view)
+	}
+}
+
+func TestPaginationAfterInfoPage(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	m := InitialModel(db.DBConfig{}, nil)
+	m.showDBList = false
+	m.animeTable.SetHeight(10)
+
+	updated, _ := m.Update(AnimeListMessage(resultsPage(0, 25, 3)))
+	m = updated.(Model)
+	if cmd := m.goToPage(2); cmd == nil {
+		t.Fatal("expected the next page to start loading")
+	}
+
+	// The info page was opened before the page arrived, so it went there instead
+	updated, _ = m.Update(navstack.ResumeNavigation{})
+	m = updated.(Model)
+	if m.pages.loading || m.pages.jumpTo != 0 {
+		t.Fatal("expected the lost load to be forgotten when coming back")
+	}
+	if cmd := m.loadMore(); cmd == nil {
+		t.Fatal("expected the next page to be able to load again")
+	}
+
+	m.addPage(resultsPageMessage{search: m.pages.search, page: 2, results: resultsPage(25, 25, 3)})
+	if len(m.searchResults) != 50 || m.pages.loading {
+		t.Fatalf("expected 50 results after the second page, got %d", len(m.searchResults))
+	}
+}
@@ -0,0 +1,38 @@
+package animelist
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/types"
+)
+
+// Imported entries that were queued while offline and have now been looked up
+type pendingMessage struct {
+	added      int
+	unresolved []string
+}
+
+// Looks up anything queued while offline, as long as we're online now. Does nothing if the queue is empty
+func (m Model) retryPendingCmd() tea.Msg {
+	if m.jikan.Offline() {
+		return nil
+	}
+
+	count, err := m.dbConfig.PendingLookupCount()
+	if err != nil {
+		return types.ErrorMsg(err.Error())
+	}
+	if count == 0 {
+		return nil
+	}
+
+	added, unresolved, err := m.dbConfig.RetryPendingLookups(db.JikanResolver(m.jikan, db.DefaultResolveThreshold))
+	if err != nil {
+		return types.ErrorMsg(err.Error())
+	}
+	if added == 0 && len(unresolved) == 0 {
+		return nil
+	}
+
+	return pendingMessage{added: added, unresolved: unresolved}
+}
@@ -0,0 +1,177 @@
+package animelist
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sahilm/fuzzy"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// The DB tab searches as you type, fuzzy matching against every title we know for each anime: the one in the list, plus the English,
+// Japanese and alternative titles from the metadata cache. Matched characters in the Name column are underlined.
+// With the FTS5 search index, synopses are searched too and results are ranked by relevance
+
+// Results of a DB tab search. Dropped if the search has changed since it was started
+type dbSearchMessage struct {
+	query string
+	anime []database.Anime
+}
+
+// Most results the search index gives back for the DB tab
+const dbSearchResults = 500
+
+// Underlining is turned off with its own code, so it doesn't undo the selected row's colours
+const (
+	highlightOn  = "\x1b[4m"
+	highlightOff = "\x1b[24m"
+)
+
+// One searchable title, and which anime in the list it belongs to
+type searchTitle struct {
+	anime int
+	title string
+}
+
+type titleSource []searchTitle
+
+func (s titleSource) String(i int) string { return s[i].title }
+func (s titleSource) Len() int            { return len(s) }
+
+// Ranks anime by their best matching title, best first. Anything without a match is left out, and an empty query keeps everything in order
+func fuzzyFilter(anime []database.Anime, otherTitles map[int64][]string, query string) []database.Anime {
+	if strings.TrimSpace(query) == "" {
+		return anime
+	}
+
+	titles := titleSource{}
+	for i, a := range anime {
+		titles = append(titles, searchTitle{anime: i, title: a.Title})
+		for _, t := range otherTitles[a.ID] {
+			titles = append(titles, searchTitle{anime: i, title: t})
+		}
+	}
+
+	best := map[int]int{}
+	for _, match := range fuzzy.FindFrom(query, titles) {
+		i := titles[match.Index].anime
+		if score, ok := best[i]; !ok || match.Score > score {
+			best[i] = match.Score
+		}
+	}
+
+	ranked := []int{}
+	for i := range best {
+		ranked = append(ranked, i)
+	}
+
+	// Ties stay in list order, so rows don't jump around while typing
+	sort.Slice(ranked, func(a, b int) bool {
+		if best[ranked[a]] != best[ranked[b]] {
+			return best[ranked[a]] > best[ranked[b]]
+		}
+		return ranked[a] < ranked[b]
+	})
+
+	results := []database.Anime{}
+	for _, i := range ranked {
+		results = append(results, anime[i])
+	}
+	return results
+}
+
+// Uses the search index when there is one, falling back to fuzzy matching if it finds nothing, e.g. for typos or abbreviations
+func (m Model) filterDB(query string) ([]database.Anime, error) {
+	if m.dbConfig.SearchIndex && strings.TrimSpace(query) != "" {
+		anime, err := m.dbConfig.SearchList(query, dbSearchResults)
+		if err != nil {
+			return nil, err
+		}
+		if len(anime) > 0 {
+			return anime, nil
+		}
+	}
+
+	anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	otherTitles, err := m.dbConfig.CachedTitles()
+	if err != nil {
+		return nil, err
+	}
+
+	return fuzzyFilter(anime, otherTitles, query), nil
+}
+
+func (m Model) searchDBByNameCmd(query string) tea.Cmd {
+	return func() tea.Msg {
+		anime, err := m.filterDB(query)
+		if err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+
+		return dbSearchMessage{query: query, anime: anime}
+	}
+}
+
+// Underlines the characters of title matching query, if it matches at all
+func highlightTitle(title, query string, width int) string {
+	if strings.TrimSpace(query) == "" {
+		return title
+	}
+
+	matches := fuzzy.Find(query, []string{title})
+	if len(matches) == 0 {
+		return title
+	}
+	return highlight(title, matches[0].MatchedIndexes, width)
+}
+
+// The table truncates cells without knowing about escape codes, so the title is cut short enough that the codes fit in width too
+func highlight(title string, matched []int, width int) string {
+	for w := width; w > 0; w-- {
+		cut := runewidth.Truncate(title, w, "")
+		tail := ""
+		if cut != title {
+			cut = runewidth.Truncate(title, w-1, "")
+			tail = "…"
+		}
+
+		underlined := underline(cut, matched) + tail
+		if runewidth.StringWidth(underlined) <= width {
+			return underlined
+		}
+	}
+	return runewidth.Truncate(title, width, "…")
+}
+
+// Wraps each run of matched bytes in highlight codes
+func underline(s string, matched []int) string {
+	isMatched := map[int]bool{}
+	for _, i := range matched {
+		isMatched[i] = true
+	}
+
+	var b strings.Builder
+	on := false
+	for i, r := range s {
+		if isMatched[i] != on {
+			on = !on
+			if on {
+				b.WriteString(highlightOn)
+			} else {
+				b.WriteString(highlightOff)
+			}
+		}
+		b.WriteRune(r)
+	}
+	if on {
+		b.WriteString(highlightOff)
+	}
+	return b.String()
+}
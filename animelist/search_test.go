@@ -0,0 +1,62 @@
+package animelist
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+	"github.com/saubuny/haru/internal/database"
+)
+
+func TestFuzzyFilter(t *testing.T) {
+	anime := []database.Anime{
+		{ID: 1, Title: "Cowboy Bebop"},
+		{ID: 2, Title: "Kaguya-sama wa Kokurasetai"},
+		{ID: 3, Title: "Bocchi the Rock!"},
+	}
+	otherTitles := map[int64][]string{
+		2: {"Kaguya-sama: Love is War"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []int64
+	}{
+		{"", []int64{1, 2, 3}},
+		{"bebop", []int64{1}},
+		{"love war", []int64{2}},
+		{"bo", []int64{3, 1}},
+		{"zzz", []int64{}},
+	}
+
+	for _, test := range tests {
+		ids := []int64{}
+		for _, a := range fuzzyFilter(anime, otherTitles, test.query) {
+			ids = append(ids, a.ID)
+		}
+		if len(ids) != len(test.expected) {
+			t.Fatalf("%q: expected %v, got %v", test.query, test.expected, ids)
+		}
+		for i := range ids {
+			if ids[i] != test.expected[i] {
+				t.Fatalf("%q: expected %v, got %v", test.query, test.expected, ids)
+			}
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	if got := highlightTitle("Cowboy Bebop", "bebop", 36); got != "Cowboy "+highlightOn+"Bebop"+highlightOff {
+		t.Fatalf("unexpected highlight %q", got)
+	}
+
+	// Long titles have to fit in the column even counting the escape codes, since that's what the table truncates by
+	title := "Sono Bisque Doll wa Koi wo Suru Season 2"
+	got := highlightTitle(title, "sbd", 20)
+	if runewidth.StringWidth(got) > 20 {
+		t.Fatalf("highlighted title is too wide for the table: %q", got)
+	}
+	if plain := ansi.Strip(got); plain[len(plain)-len("…"):] != "…" {
+		t.Fatalf("expected a truncated title, got %q", plain)
+	}
+}
@@ -0,0 +1,343 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/saubuny/haru/animeinfo"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/types"
+)
+
+// When everything being watched airs next, for the week ahead in local time. Broadcast times come from the metadata cache, and anything
+// missing or stale is fetched when the screen opens. Anime with episodes out since the last progress update are highlighted
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	dayStyle      = lipgloss.NewStyle().Bold(true)
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	newStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("214"))
+	selectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+)
+
+const titleWidth = 40
+
+// Everything being watched, and whatever details we have for each
+type scheduleMessage struct {
+	watching []database.Anime
+	details  map[int64]types.AnimeData
+}
+
+// Sent every minute so the schedule moves along while it's open
+type tickMessage time.Time
+
+type Model struct {
+	width  int
+	height int
+
+	dbConfig db.DBConfig
+	jikan    *jikan.Client
+
+	now      time.Time
+	watching []database.Anime
+	details  map[int64]types.AnimeData
+
+	days    []day
+	unknown []entry
+	// Every entry in the order shown, for moving the cursor through
+	shown  []entry
+	cursor int
+
+	help    help.Model
+	spinner spinner.Model
+
+	showHelp    bool
+	showSpinner bool
+}
+
+func New(db db.DBConfig, client *jikan.Client) Model {
+	help := help.New()
+	help.ShowAll = true
+
+	s := spinner.New()
+	s.Spinner = spinner.Points
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	return Model{
+		dbConfig:    db,
+		jikan:       client,
+		now:         time.Now(),
+		help:        help,
+		spinner:     s,
+		showHelp:    true,
+		showSpinner: true,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.loadCmd(false), tick())
+}
+
+func tick() tea.Cmd {
+	return tea.Every(time.Minute, func(t time.Time) tea.Msg {
+		return tickMessage(t)
+	})
+}
+
+// Loads everything being watched with its details. Details that aren't cached or are stale get fetched, or all of them if refresh is set.
+// Failing to fetch isn't a big deal, since the cached details will do
+func (m Model) loadCmd(refresh bool) tea.Cmd {
+	return func() tea.Msg {
+		anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
+		if err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+
+		watching := []database.Anime{}
+		details := map[int64]types.AnimeData{}
+		for _, a := range anime {
+			if a.Completion != types.Watching {
+				continue
+			}
+			watching = append(watching, a)
+
+			cached, fetched, err := m.dbConfig.CachedAnime(a.ID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return types.ErrorMsg(err.Error())
+			}
+			if err == nil {
+				details[a.ID] = cached
+				// Finished anime won't start airing again, so there's no point checking
+				if !cached.Airing && cached.Status == "Finished Airing" {
+					continue
+				}
+			}
+			if err == nil && !refresh && time.Since(fetched) < db.DefaultCacheTTL {
+				continue
+			}
+			if m.jikan.Offline() {
+				continue
+			}
+
+			res, err := m.jikan.GetAnime(context.Background(), int(a.ID))
+			if err != nil {
+				log.Printf("Could not fetch anime %d for the calendar: %v", a.ID, err)
+				continue
+			}
+			details[a.ID] = res.Data
+			if err := m.dbConfig.CacheAnime(res.Data); err != nil {
+				log.Printf("Could not cache anime %d: %v", a.ID, err)
+			}
+		}
+
+		return scheduleMessage{watching: watching, details: details}
+	}
+}
+
+// Works the schedule out again, as of m.now, keeping the cursor on the same anime
+func (m *Model) rebuild() {
+	selected, hadSelected := m.selected()
+
+	m.days, m.unknown = week(entries(m.watching, m.details, m.now), m.now)
+	m.shown = []entry{}
+	for _, d := range m.days {
+		m.shown = append(m.shown, d.entries...)
+	}
+	m.shown = append(m.shown, m.unknown...)
+
+	m.cursor = min(m.cursor, max(0, len(m.shown)-1))
+	if hadSelected {
+		for i, e := range m.shown {
+			if e.anime.ID == selected.anime.ID {
+				m.cursor = i
+			}
+		}
+	}
+}
+
+func (m Model) selected() (entry, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.shown) {
+		return entry{}, false
+	}
+	return m.shown[m.cursor], true
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case types.ErrorMsg:
+		// Shown in the status bar by navstack
+		m.showSpinner = false
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case scheduleMessage:
+		m.watching = msg.watching
+		m.details = msg.details
+		m.now = time.Now()
+		m.showSpinner = false
+		m.rebuild()
+		return m, nil
+	case tickMessage:
+		m.now = time.Time(msg)
+		m.rebuild()
+		return m, tick()
+	case navstack.ResumeNavigation:
+		// Progress might have been updated on the info page
+		return m, m.loadCmd(false)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, CalendarKeyMap.Esc):
+			return m, navstack.Cmd(navstack.PopNavigation{})
+		case key.Matches(msg, CalendarKeyMap.Help):
+			m.showHelp = !m.showHelp
+			return m, nil
+		case key.Matches(msg, CalendarKeyMap.Up):
+			m.cursor = max(0, m.cursor-1)
+			return m, nil
+		case key.Matches(msg, CalendarKeyMap.Down):
+			m.cursor = max(0, min(m.cursor+1, len(m.shown)-1))
+			return m, nil
+		case key.Matches(msg, CalendarKeyMap.Refresh):
+			m.showSpinner = true
+			return m, tea.Batch(m.spinner.Tick, m.loadCmd(true))
+		case key.Matches(msg, CalendarKeyMap.Select):
+			e, ok := m.selected()
+			if !ok {
+				return m, nil
+			}
+			return m, tea.Sequence(
+				navstack.Cmd(navstack.PushNavigation{Item: animeinfo.New(m.dbConfig, e.anime.ID)}),
+				navstack.Cmd(types.AnimeDataMessage{Data: e.data}),
+			)
+		}
+	}
+
+	m.spinner, cmd = m.spinner.Update(msg)
+	return m, cmd
+}
+
+// e.g. Today, Tomorrow or Monday 21 Oct
+func dayName(date, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch {
+	case date.Equal(today):
+		return "Today"
+	case date.Equal(today.AddDate(0, 0, 1)):
+		return "Tomorrow"
+	}
+	return date.Format("Monday 2 Jan")
+}
+
+func (m Model) entryView(e entry, selected bool) string {
+	clock := "     "
+	if e.known {
+		clock = e.next.Format("15:04")
+	}
+
+	progress := fmt.Sprintf("%d watched", e.anime.Watchedepisodes)
+	if e.data.Episodes > 0 {
+		progress = fmt.Sprintf("%d/%d watched", e.anime.Watchedepisodes, e.data.Episodes)
+	}
+
+	title := runewidth.FillRight(runewidth.Truncate(e.anime.Title, titleWidth, "…"), titleWidth)
+	line := fmt.Sprintf("  %s  %s  %s", clock, title, dimStyle.Render(progress))
+	if e.unwatched > 0 {
+		line += "  " + newStyle.Render(fmt.Sprintf("%d new since you last edited this entry", e.unwatched))
+	}
+
+	if selected {
+		return selectedStyle.Render(">") + line[1:]
+	}
+	return line
+}
+
+// Every line of the schedule, and which one the cursor is on
+func (m Model) lines() ([]string, int) {
+	lines := []string{}
+	cursorLine := 0
+	i := 0
+
+	add := func(e entry) {
+		if i == m.cursor {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, m.entryView(e, i == m.cursor))
+		i++
+	}
+
+	for n, d := range m.days {
+		// The last day is only there for anything airing a week from now
+		if n == len(m.days)-1 && len(d.entries) == 0 {
+			continue
+		}
+
+		lines = append(lines, dayStyle.Render(dayName(d.date, m.now)))
+		if len(d.entries) == 0 {
+			lines = append(lines, dimStyle.Render("  Nothing airing"))
+		}
+		for _, e := range d.entries {
+			add(e)
+		}
+		lines = append(lines, "")
+	}
+
+	if len(m.unknown) > 0 {
+		lines = append(lines, dayStyle.Render("Broadcast time unknown"))
+		for _, e := range m.unknown {
+			add(e)
+		}
+	}
+	return lines, cursorLine
+}
+
+func (m Model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	header := titleStyle.Render("Airing this week") + dimStyle.Render(fmt.Sprintf("  times in %s", m.now.Format("MST")))
+	if m.showSpinner {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, header+"\n\n"+m.spinner.View())
+	}
+
+	footer := ""
+	if m.showHelp {
+		footer = m.help.View(CalendarKeyMap)
+	}
+
+	body := ""
+	if len(m.shown) == 0 {
+		body = "Nothing you're watching is airing right now"
+	} else {
+		// Scrolls to keep the cursor in the middle once the schedule is too long to fit
+		lines, cursorLine := m.lines()
+		height := max(1, m.height-lipgloss.Height(footer)-3)
+		start := min(max(0, cursorLine-height/2), max(0, len(lines)-height))
+		end := min(len(lines), start+height)
+		body = strings.Join(lines[start:end], "\n")
+	}
+
+	render := header + "\n\n" + body
+	if footer != "" {
+		render += "\n" + footer
+	}
+	render = lipgloss.NewStyle().Width(int(float64(m.width) * 0.8)).Render(render)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+}
@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// Jikan gives broadcast times in Japan. A fixed zone is enough since Japan doesn't have daylight saving, and it doesn't depend on tzdata being installed
+var jst = time.FixedZone("JST", 9*60*60)
+
+// When an anime airs each week, in Japan
+type broadcast struct {
+	day    time.Weekday
+	hour   int
+	minute int
+}
+
+// Reads Jikan's broadcast info, which looks like {"day": "Saturdays", "time": "23:30", "timezone": "Asia/Tokyo"}.
+// ok is false when MAL doesn't know, which is common for ONAs
+func parseBroadcast(day, clock, timezone string) (b broadcast, ok bool) {
+	if timezone != "" && timezone != "Asia/Tokyo" {
+		return broadcast{}, false
+	}
+
+	found := false
+	day = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(day), "s"))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToLower(d.String()) == day {
+			b.day, found = d, true
+		}
+	}
+	if !found {
+		return broadcast{}, false
+	}
+
+	hour, minute, ok := strings.Cut(clock, ":")
+	if !ok {
+		return broadcast{}, false
+	}
+	var err error
+	if b.hour, err = strconv.Atoi(hour); err != nil || b.hour < 0 || b.hour > 23 {
+		return broadcast{}, false
+	}
+	if b.minute, err = strconv.Atoi(minute); err != nil || b.minute < 0 || b.minute > 59 {
+		return broadcast{}, false
+	}
+	return b, true
+}
+
+// The first broadcast after now, in now's time zone
+func (b broadcast) next(now time.Time) time.Time {
+	japan := now.In(jst)
+	days := (int(b.day) - int(japan.Weekday()) + 7) % 7
+	airs := time.Date(japan.Year(), japan.Month(), japan.Day()+days, b.hour, b.minute, 0, 0, jst)
+	if !airs.After(now) {
+		airs = airs.AddDate(0, 0, 7)
+	}
+	return airs.In(now.Location())
+}
+
+// How many times it has been broadcast after since, up to now
+func (b broadcast) airedBetween(since, now time.Time) int {
+	if !now.After(since) {
+		return 0
+	}
+
+	first := b.next(since)
+	if first.After(now) {
+		return 0
+	}
+	return int(now.Sub(first)/(7*24*time.Hour)) + 1
+}
+
+// An anime being watched that's still airing
+type entry struct {
+	anime    database.Anime
+	data     types.AnimeData
+	schedule broadcast
+	known    bool // whether the broadcast time is known
+
+	next time.Time
+	// Episodes broadcast since the last time progress was updated
+	unwatched int
+}
+
+// A day of the week, and what airs on it
+type day struct {
+	date    time.Time
+	entries []entry
+}
+
+// Works out when each anime airs next and how many new episodes there are, as of now. Only airing anime are kept.
+// Only the date an entry was last edited is kept, and any edit counts (a new score too), so anything broadcast up to the day of the last edit is
+// assumed to have been watched
+func entries(watching []database.Anime, details map[int64]types.AnimeData, now time.Time) []entry {
+	all := []entry{}
+	for _, anime := range watching {
+		data, ok := details[anime.ID]
+		if !ok || !data.Airing {
+			continue
+		}
+
+		e := entry{anime: anime, data: data}
+		e.schedule, e.known = parseBroadcast(data.Broadcast.Day, data.Broadcast.Time, data.Broadcast.Timezone)
+		if e.known {
+			e.next = e.schedule.next(now)
+
+			if updated, err := time.ParseInLocation("2006-01-02", anime.Updateddate, now.Location()); err == nil {
+				e.unwatched = e.schedule.airedBetween(updated.AddDate(0, 0, 1), now)
+			}
+			// Can't be more new episodes than are left
+			if remaining := int(int64(data.Episodes) - anime.Watchedepisodes); data.Episodes > 0 {
+				e.unwatched = max(0, min(e.unwatched, remaining))
+			}
+		}
+		all = append(all, e)
+	}
+	return all
+}
+
+// Groups entries by the day they next air, starting from today. Everything airs within a week, but that can be on today's weekday next week, so there
+// are 8 days. Anything without a known broadcast time is returned separately
+func week(all []entry, now time.Time) (days []day, unknown []entry) {
+	for i := range 8 {
+		days = append(days, day{date: time.Date(now.Year(), now.Month(), now.Day()+i, 0, 0, 0, 0, now.Location())})
+	}
+
+	for _, e := range all {
+		if !e.known {
+			unknown = append(unknown, e)
+			continue
+		}
+
+		// Counting calendar days rather than hours, since daylight saving can make a day 23 or 25 hours long
+		i := len(days) - 1
+		for i > 0 && e.next.Before(days[i].date) {
+			i--
+		}
+		days[i].entries = append(days[i].entries, e)
+	}
+
+	for _, d := range days {
+		sort.SliceStable(d.entries, func(i, j int) bool {
+			return d.entries[i].next.Before(d.entries[j].next)
+		})
+	}
+	sort.SliceStable(unknown, func(i, j int) bool {
+		return unknown[i].anime.Title < unknown[j].anime.Title
+	})
+	return days, unknown
+}
@@ -0,0 +1,140 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+func TestParseBroadcast(t *testing.T) {
+	b, ok := parseBroadcast("Saturdays", "23:30", "Asia/Tokyo")
+	if !ok || b != (broadcast{day: time.Saturday, hour: 23, minute: 30}) {
+		t.Fatalf("unexpected broadcast %#v", b)
+	}
+
+	for _, bad := range [][3]string{
+		{"Unknown", "", ""},
+		{"Saturdays", "", "Asia/Tokyo"},
+		{"Saturdays", "25:00", "Asia/Tokyo"},
+		{"Saturdays", "23:30", "America/New_York"},
+	} {
+		if _, ok := parseBroadcast(bad[0], bad[1], bad[2]); ok {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	// Saturday 23:30 in Japan is Saturday 14:30 UTC, and Saturday 09:30 in New York during summer time
+	b := broadcast{day: time.Saturday, hour: 23, minute: 30}
+	newYork := time.FixedZone("EDT", -4*60*60)
+
+	tests := []struct {
+		now      time.Time
+		expected time.Time
+	}{
+		// Wednesday, so later this week
+		{time.Date(2024, 10, 16, 12, 0, 0, 0, time.UTC), time.Date(2024, 10, 19, 14, 30, 0, 0, time.UTC)},
+		// Just after it aired, so next week
+		{time.Date(2024, 10, 19, 14, 31, 0, 0, time.UTC), time.Date(2024, 10, 26, 14, 30, 0, 0, time.UTC)},
+		// Already Sunday in Japan but still Saturday in New York
+		{time.Date(2024, 10, 19, 12, 0, 0, 0, newYork), time.Date(2024, 10, 26, 10, 30, 0, 0, newYork)},
+		{time.Date(2024, 10, 19, 9, 0, 0, 0, newYork), time.Date(2024, 10, 19, 10, 30, 0, 0, newYork)},
+	}
+
+	for _, test := range tests {
+		got := b.next(test.now)
+		if !got.Equal(test.expected) || got.Location() != test.now.Location() {
+			t.Errorf("%v: expected %v, got %v", test.now, test.expected, got)
+		}
+	}
+}
+
+func TestAiredBetween(t *testing.T) {
+	b := broadcast{day: time.Saturday, hour: 23, minute: 30}
+	since := time.Date(2024, 10, 1, 0, 0, 0, 0, jst)
+
+	tests := []struct {
+		now      time.Time
+		expected int
+	}{
+		{time.Date(2024, 10, 5, 23, 0, 0, 0, jst), 0},
+		{time.Date(2024, 10, 5, 23, 30, 0, 0, jst), 1},
+		{time.Date(2024, 10, 19, 23, 29, 0, 0, jst), 2},
+		{time.Date(2024, 10, 19, 23, 30, 0, 0, jst), 3},
+	}
+
+	for _, test := range tests {
+		if got := b.airedBetween(since, test.now); got != test.expected {
+			t.Errorf("%v: expected %d, got %d", test.now, test.expected, got)
+		}
+	}
+}
+
+func TestWeek(t *testing.T) {
+	airing := func(id int64, title, day, clock string, episodes int) (database.Anime, types.AnimeData) {
+		data := types.AnimeData{MalID: int(id), Title: title, Airing: true, Episodes: episodes}
+		data.Broadcast.Day, data.Broadcast.Time, data.Broadcast.Timezone = day, clock, "Asia/Tokyo"
+		return database.Anime{ID: id, Title: title, Completion: types.Watching, Watchedepisodes: 1, Updateddate: "2024-10-01"}, data
+	}
+
+	details := map[int64]types.AnimeData{}
+	watching := []database.Anime{}
+	for _, a := range [][4]string{
+		{"1", "Dandadan", "Fridays", "00:26"},
+		{"2", "Re:Zero", "Wednesdays", "23:00"},
+		{"3", "Some ONA", "", ""},
+		{"4", "Late Wednesday", "Wednesdays", "23:30"},
+	} {
+		id := int64(len(watching) + 1)
+		anime, data := airing(id, a[1], a[2], a[3], 2)
+		watching = append(watching, anime)
+		details[id] = data
+	}
+	finished, data := airing(5, "Finished", "Sundays", "12:00", 12)
+	data.Airing = false
+	watching = append(watching, finished)
+	details[5] = data
+	long, data := airing(6, "One Piece", "Sundays", "09:30", 1100)
+	watching = append(watching, long)
+	details[6] = data
+
+	// Wednesday 23:10 in Japan, so Re:Zero has just aired and the late one airs soon
+	now := time.Date(2024, 10, 16, 23, 10, 0, 0, jst)
+	days, unknown := week(entries(watching, details, now), now)
+
+	if len(days) != 8 || len(unknown) != 1 || unknown[0].anime.ID != 3 {
+		t.Fatalf("expected 8 days and the ONA without a time, got %d days and %v", len(days), unknown)
+	}
+
+	ids := func(d day) []int64 {
+		all := []int64{}
+		for _, e := range d.entries {
+			all = append(all, e.anime.ID)
+		}
+		return all
+	}
+	// Today (Wednesday), Friday 00:26 is two days away, and Re:Zero is next Wednesday
+	if got := ids(days[0]); len(got) != 1 || got[0] != 4 {
+		t.Fatalf("expected the late Wednesday anime today, got %v", got)
+	}
+	if got := ids(days[2]); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("expected Dandadan on Friday, got %v", got)
+	}
+	if got := ids(days[7]); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("expected Re:Zero a week from today, got %v", got)
+	}
+
+	// Re:Zero has aired three times since the 1st and the other two twice, but they only have one episode left. One Piece has plenty left, so
+	// both of its Sundays count
+	expected := map[int64]int{1: 1, 2: 1, 4: 1, 6: 2}
+	for _, d := range days {
+		for _, e := range d.entries {
+			if e.unwatched != expected[e.anime.ID] {
+				t.Errorf("%s: expected %d new episodes, got %d", e.anime.Title, expected[e.anime.ID], e.unwatched)
+			}
+		}
+	}
+}
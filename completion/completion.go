@@ -0,0 +1,59 @@
+package completion
+
+// The completion picker shared by the list and the info page
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/types"
+)
+
+var PopupStyle = lipgloss.NewStyle().
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("57")).
+	Padding(0, 1)
+
+// In the order they're shown in the picker
+var All = []string{types.Watching, types.Completed, types.OnHold, types.Dropped, types.PlanToWatch}
+
+type Item string
+
+func (i Item) Title() string       { return string(i) }
+func (i Item) Description() string { return "" }
+func (i Item) FilterValue() string { return string(i) }
+
+func NewSelector() list.Model {
+	items := []list.Item{}
+	for _, c := range All {
+		items = append(items, Item(c))
+	}
+
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = false
+	delegate.SetSpacing(0)
+
+	// Title takes up 2 lines on top of the items
+	sel := list.New(items, delegate, 20, len(items)+2)
+	sel.Title = "Completion"
+	sel.SetShowStatusBar(false)
+	sel.SetShowPagination(false)
+	sel.SetShowHelp(false)
+	sel.SetFilteringEnabled(false)
+	sel.DisableQuitKeybindings()
+	return sel
+}
+
+// Moves the selector to completion, or to Plan To Watch if it isn't a known completion
+func Select(sel *list.Model, completion string) {
+	sel.Select(len(All) - 1)
+	for i, c := range All {
+		if c == completion {
+			sel.Select(i)
+		}
+	}
+}
+
+// View of the selector drawn as a popup
+func View(sel list.Model) string {
+	return PopupStyle.Render(sel.View())
+}
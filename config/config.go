@@ -0,0 +1,169 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Settings read from $XDG_CONFIG_HOME/haru/config.json. Every field is optional
+type Config struct {
+	// Where the database lives, ~ is expanded
+	DB string `json:"db"`
+}
+
+const (
+	appName    = "haru"
+	dbFileName = "anime.db"
+)
+
+// Where haru keeps its data, usually ~/.local/share/haru
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", ".local/share")
+}
+
+// Where haru reads config.json from, usually ~/.config/haru
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// Where haru keeps its log file, usually ~/.local/state/haru
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", ".local/state")
+}
+
+// Opens the log file for appending, creating it and its directory if needed
+func LogFile() (*os.File, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(filepath.Join(dir, "haru.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+func xdgDir(env string, fallback string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	// The spec says relative XDG paths are invalid and should be ignored
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, appName), nil
+}
+
+// Reads the config file. A missing file is the same as an empty one
+func Load() (Config, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	file, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(file, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Works out where the database lives: explicit (from --db or HARU_DB) wins, then the config file, then the XDG data dir. isDefault is only true for the XDG data dir
+func (cfg Config) DBPath(explicit string) (path string, isDefault bool, err error) {
+	if explicit != "" {
+		path, err = expandHome(explicit)
+		return path, false, err
+	}
+
+	if cfg.DB != "" {
+		path, err = expandHome(cfg.DB)
+		return path, false, err
+	}
+
+	dir, err := DataDir()
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Join(dir, dbFileName), true, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// Creates the directories the database goes in. If the database doesn't exist yet but an old ./anime.db does, that gets moved there instead, since older versions always used the working directory
+func PrepareDB(path string, isDefault bool) (moved bool, err error) {
+	if path == ":memory:" {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+
+	if !isDefault {
+		return false, nil
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	if _, err := os.Stat(dbFileName); err != nil {
+		return false, nil
+	}
+
+	return true, moveFile(dbFileName, path)
+}
+
+// os.Rename can't move across filesystems, so fall back to copying
+func moveFile(from string, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(from)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDBPath(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	path, isDefault, err := Config{}.DBPath("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := filepath.Join(dataHome, "haru", "anime.db"); path != expected || !isDefault {
+		t.Fatalf("expected default %s, got %s (%v)", expected, path, isDefault)
+	}
+
+	path, isDefault, _ = Config{DB: "/from/config.db"}.DBPath("")
+	if path != "/from/config.db" || isDefault {
+		t.Fatalf("expected config file path, got %s (%v)", path, isDefault)
+	}
+
+	path, isDefault, _ = Config{DB: "/from/config.db"}.DBPath("/from/flag.db")
+	if path != "/from/flag.db" || isDefault {
+		t.Fatalf("expected flag path, got %s (%v)", path, isDefault)
+	}
+}
+
+func TestPrepareDBMovesStrayDB(t *testing.T) {
+	workDir := t.TempDir()
+	oldDir, _ := os.Getwd()
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(oldDir) })
+	if err := os.WriteFile("anime.db", []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(t.TempDir(), "nested", "haru", "anime.db")
+	moved, err := PrepareDB(target, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !moved {
+		t.Fatal("expected ./anime.db to be moved")
+	}
+
+	if contents, err := os.ReadFile(target); err != nil || string(contents) != "old" {
+		t.Fatalf("target does not contain the old database: %q %v", contents, err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "anime.db")); err == nil {
+		t.Fatal("old database was left behind")
+	}
+
+	// Only happens once, later runs leave a new stray file alone
+	os.WriteFile("anime.db", []byte("new"), 0o644)
+	if moved, _ := PrepareDB(target, true); moved {
+		t.Fatal("database should not be moved over an existing one")
+	}
+}
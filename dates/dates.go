@@ -0,0 +1,94 @@
+package dates
+
+// Dates are stored as text so that partial dates work: 2024-01-31, 2024-01 or just 2024. Unknown dates are NULL
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dayLayout   = "2006-01-02"
+	monthLayout = "2006-01"
+	yearLayout  = "2006"
+)
+
+// What to type in a date prompt to clear the date
+const Unknown = "unknown"
+
+var relativeRegex = regexp.MustCompile(`^-(\d+)([dw])$`)
+
+// Turns what was typed into a date prompt into a stored date. Accepts full or partial dates, today, yesterday, relative days/weeks like -3d or -2w, and unknown (or nothing) to clear it
+func Parse(input string, now time.Time) (sql.NullString, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+
+	switch input {
+	case "", Unknown:
+		return sql.NullString{}, nil
+	case "today":
+		return day(now), nil
+	case "yesterday":
+		return day(now.AddDate(0, 0, -1)), nil
+	}
+
+	if match := relativeRegex.FindStringSubmatch(input); match != nil {
+		n, _ := strconv.Atoi(match[1])
+		if match[2] == "w" {
+			n *= 7
+		}
+		return day(now.AddDate(0, 0, -n)), nil
+	}
+
+	for _, layout := range []string{dayLayout, monthLayout, yearLayout} {
+		if t, err := time.Parse(layout, input); err == nil {
+			if t.After(now) {
+				return sql.NullString{}, fmt.Errorf("%s is in the future", input)
+			}
+			return sql.NullString{String: input, Valid: true}, nil
+		}
+	}
+
+	return sql.NullString{}, fmt.Errorf("expected YYYY-MM-DD, YYYY-MM, YYYY, today, yesterday, -3d or %s", Unknown)
+}
+
+// Cleans up a date coming from an import. MAL uses 0000-00-00 for unknown dates and zeroes out unknown months and days
+func Normalise(raw string) sql.NullString {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.HasPrefix(raw, "0000") {
+		return sql.NullString{}
+	}
+
+	raw = strings.TrimSuffix(raw, "-00")
+	raw = strings.TrimSuffix(raw, "-00")
+
+	for _, layout := range []string{dayLayout, monthLayout, yearLayout} {
+		if _, err := time.Parse(layout, raw); err == nil {
+			return sql.NullString{String: raw, Valid: true}
+		}
+	}
+	return sql.NullString{}
+}
+
+// How a date is shown everywhere in the UI
+func Display(date sql.NullString) string {
+	if !date.Valid {
+		return "-"
+	}
+	return date.String
+}
+
+// The value a date prompt starts with, so that pressing enter keeps the date as is
+func Edit(date sql.NullString) string {
+	if !date.Valid {
+		return Unknown
+	}
+	return date.String
+}
+
+func day(t time.Time) sql.NullString {
+	return sql.NullString{String: t.Format(dayLayout), Valid: true}
+}
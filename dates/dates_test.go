@@ -0,0 +1,59 @@
+package dates
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		input    string
+		expected sql.NullString
+	}{
+		{"", sql.NullString{}},
+		{"Unknown", sql.NullString{}},
+		{"today", sql.NullString{String: "2024-03-10", Valid: true}},
+		{"yesterday", sql.NullString{String: "2024-03-09", Valid: true}},
+		{"-3d", sql.NullString{String: "2024-03-07", Valid: true}},
+		{"-2w", sql.NullString{String: "2024-02-25", Valid: true}},
+		{" 2021-07-06 ", sql.NullString{String: "2021-07-06", Valid: true}},
+		{"2021-07", sql.NullString{String: "2021-07", Valid: true}},
+		{"2021", sql.NullString{String: "2021", Valid: true}},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.input, now)
+		if err != nil {
+			t.Fatalf("%q: %v", test.input, err)
+		}
+		if got != test.expected {
+			t.Fatalf("%q: expected %#v, got %#v", test.input, test.expected, got)
+		}
+	}
+
+	for _, input := range []string{"2021-13-01", "2021-02-30", "last week", "+3d", "2025-01-01"} {
+		if _, err := Parse(input, now); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestNormalise(t *testing.T) {
+	tests := map[string]sql.NullString{
+		"":           {},
+		"0000-00-00": {},
+		"2020-00-00": {String: "2020", Valid: true},
+		"2020-05-00": {String: "2020-05", Valid: true},
+		"2020-05-17": {String: "2020-05-17", Valid: true},
+		"garbage":    {},
+	}
+
+	for raw, expected := range tests {
+		if got := Normalise(raw); got != expected {
+			t.Fatalf("%q: expected %#v, got %#v", raw, expected, got)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// AniList keeps the MAL ID of nearly everything in idMal, so entries can be added straight away. The few that aren't on MAL are returned as unresolved.
+// Custom lists are skipped since everything in them is in one of the status lists too
+func (cfg DBConfig) ImportAniList(data []byte) ([]string, error) {
+	var export types.AniList
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, err
+	}
+
+	collection := export.AniListCollection
+	if len(export.Data.MediaListCollection.Lists) > 0 {
+		collection = export.Data.MediaListCollection
+	}
+
+	entries := []types.AniListEntry{}
+	for _, list := range collection.Lists {
+		if list.IsCustomList {
+			continue
+		}
+		for _, entry := range list.Entries {
+			if entry.Media.Type != "" && entry.Media.Type != "ANIME" {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	format := collection.User.MediaListOptions.ScoreFormat
+	if format == "" {
+		format = guessAniListScoreFormat(entries)
+	}
+
+	// Checking every entry before saving any, then saving them together like restoreAll
+	unresolved := []string{}
+	rows := []database.Anime{}
+	seen := map[int]bool{}
+	for _, entry := range entries {
+		title := aniListTitle(entry)
+
+		completion, ok := aniListCompletion(entry.Status)
+		if !ok {
+			return nil, fmt.Errorf("unknown anilist status %q for %s", entry.Status, title)
+		}
+
+		if entry.Media.IDMal == nil || *entry.Media.IDMal <= 0 {
+			unresolved = append(unresolved, fmt.Sprintf("%s (https://anilist.co/anime/%d)", title, entry.Media.ID))
+			continue
+		}
+		if seen[*entry.Media.IDMal] {
+			continue
+		}
+		seen[*entry.Media.IDMal] = true
+
+		rows = append(rows, database.Anime{
+			ID:              int64(*entry.Media.IDMal),
+			Title:           title,
+			Startdate:       dates.Normalise(aniListDate(entry.StartedAt)),
+			Completion:      completion,
+			Watchedepisodes: int64(max(0, entry.Progress)),
+			Score:           aniListScore(entry.Score, format),
+			Finishdate:      dates.Normalise(aniListDate(entry.CompletedAt)),
+			Timeswatched:    int64(max(0, entry.Repeat)),
+		})
+	}
+
+	log.Printf("Importing Anime...")
+	if err := cfg.saveAll(rows, false); err != nil {
+		return nil, err
+	}
+	return unresolved, nil
+}
+
+func aniListCompletion(status string) (string, bool) {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "CURRENT":
+		return types.Watching, true
+	// Rewatching is still watching, and the rewatch count is kept separately
+	case "REPEATING":
+		return types.Watching, true
+	case "PLANNING":
+		return types.PlanToWatch, true
+	case "COMPLETED":
+		return types.Completed, true
+	case "PAUSED":
+		return types.OnHold, true
+	case "DROPPED":
+		return types.Dropped, true
+	}
+	return "", false
+}
+
+// MAL titles are romaji, so that's preferred when there's a choice
+func aniListTitle(entry types.AniListEntry) string {
+	t := entry.Media.Title
+	for _, title := range []string{t.Romaji, t.UserPreferred, t.English, t.Native} {
+		if title != "" {
+			return title
+		}
+	}
+	return fmt.Sprintf("AniList %d", entry.Media.ID)
+}
+
+// e.g. 2024-10-18, or 2024-10 when the day isn't known. Empty when there's no year
+func aniListDate(d types.AniListDate) string {
+	if d.Year == nil {
+		return ""
+	}
+
+	date := fmt.Sprintf("%04d", *d.Year)
+	if d.Month != nil {
+		date += fmt.Sprintf("-%02d", *d.Month)
+		if d.Day != nil {
+			date += fmt.Sprintf("-%02d", *d.Day)
+		}
+	}
+	return date
+}
+
+// Scores are in whatever format the user picked on AniList. Anything over 10 can only be out of 100, otherwise it's assumed to already be out of 10
+func guessAniListScoreFormat(entries []types.AniListEntry) string {
+	for _, entry := range entries {
+		if entry.Score > 10 {
+			return "POINT_100"
+		}
+	}
+	return "POINT_10"
+}
+
+// Converts a score to MAL's 1-10, keeping 0 as no score. Low scores out of 100 round up to 1 rather than becoming no score
+func aniListScore(score float64, format string) int64 {
+	if score <= 0 {
+		return 0
+	}
+
+	switch format {
+	case "POINT_100":
+		score /= 10
+	case "POINT_5":
+		score *= 2
+	case "POINT_3":
+		score *= 3
+	}
+	return int64(min(10, max(1, math.Round(score))))
+}
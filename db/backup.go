@@ -0,0 +1,260 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// haru's own JSON and CSV formats, which keep every tracked field so the list can be restored exactly. See the README for what each field means
+
+// The newest version of the JSON format this build writes and understands
+const HaruListVersion = 1
+
+// CSV columns, in the order they're written. Imports find columns by name, so they can be reordered or left out (apart from id, title and completion)
+var csvColumns = []string{"id", "title", "completion", "watched_episodes", "score", "times_watched", "start_date", "finish_date", "updated_date"}
+
+func toHaruAnime(anime database.Anime) types.HaruAnime {
+	h := types.HaruAnime{
+		ID:              anime.ID,
+		Title:           anime.Title,
+		Completion:      anime.Completion,
+		WatchedEpisodes: anime.Watchedepisodes,
+		Score:           anime.Score,
+		TimesWatched:    anime.Timeswatched,
+		UpdatedDate:     anime.Updateddate,
+	}
+	if anime.Startdate.Valid {
+		h.StartDate = &anime.Startdate.String
+	}
+	if anime.Finishdate.Valid {
+		h.FinishDate = &anime.Finishdate.String
+	}
+	return h
+}
+
+// A date from an import, which has to be valid if it's there at all
+func importDate(raw *string) (sql.NullString, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return sql.NullString{}, nil
+	}
+
+	date := dates.Normalise(*raw)
+	if !date.Valid {
+		return sql.NullString{}, fmt.Errorf("invalid date %q", *raw)
+	}
+	return date, nil
+}
+
+// Checks an imported entry and turns it into a row. Entries without an updated date count as updated today
+func fromHaruAnime(h types.HaruAnime) (database.Anime, error) {
+	if h.ID <= 0 {
+		return database.Anime{}, fmt.Errorf("invalid id %d", h.ID)
+	}
+
+	switch h.Completion {
+	case types.Watching, types.Completed, types.OnHold, types.Dropped, types.PlanToWatch:
+	default:
+		return database.Anime{}, fmt.Errorf("unknown completion %q", h.Completion)
+	}
+
+	if h.WatchedEpisodes < 0 || h.TimesWatched < 0 {
+		return database.Anime{}, fmt.Errorf("negative episode or rewatch count")
+	}
+	if h.Score < 0 || h.Score > 10 {
+		return database.Anime{}, fmt.Errorf("score %d isn't between 0 and 10", h.Score)
+	}
+
+	anime := database.Anime{
+		ID:              h.ID,
+		Title:           h.Title,
+		Completion:      h.Completion,
+		Watchedepisodes: h.WatchedEpisodes,
+		Score:           h.Score,
+		Timeswatched:    h.TimesWatched,
+		Updateddate:     h.UpdatedDate,
+	}
+
+	var err error
+	if anime.Startdate, err = importDate(h.StartDate); err != nil {
+		return database.Anime{}, err
+	}
+	if anime.Finishdate, err = importDate(h.FinishDate); err != nil {
+		return database.Anime{}, err
+	}
+
+	if anime.Updateddate == "" {
+		anime.Updateddate = time.Now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", anime.Updateddate); err != nil {
+		return database.Anime{}, fmt.Errorf("invalid updated date %q", anime.Updateddate)
+	}
+	return anime, nil
+}
+
+// Checks every entry before saving any, then saves them all in one transaction, so neither a bad file nor a failed write leaves the list half imported
+func (cfg DBConfig) restoreAll(list []types.HaruAnime) error {
+	rows := []database.Anime{}
+	for i, h := range list {
+		anime, err := fromHaruAnime(h)
+		if err != nil {
+			return fmt.Errorf("entry %d (%s): %w", i+1, h.Title, err)
+		}
+		rows = append(rows, anime)
+	}
+
+	log.Printf("Importing Anime...")
+	return cfg.saveAll(rows, true)
+}
+
+// Writes the whole list in haru's JSON format
+func (cfg DBConfig) ExportJSON(w io.Writer) error {
+	anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		return err
+	}
+
+	list := types.HaruList{
+		Version:  HaruListVersion,
+		Exported: time.Now().Format(time.RFC3339),
+		Anime:    []types.HaruAnime{},
+	}
+	for _, a := range anime {
+		list.Anime = append(list.Anime, toHaruAnime(a))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// Restores a list exported with ExportJSON, overwriting entries already in the list. Files from newer versions of haru are refused, since their fields
+// might not mean the same thing any more
+func (cfg DBConfig) ImportJSON(data []byte) error {
+	var list types.HaruList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	if list.Version < 1 {
+		return fmt.Errorf("not a haru export (missing version)")
+	}
+	if list.Version > HaruListVersion {
+		return fmt.Errorf("exported by a newer version of haru (format version %d, this version reads up to %d)", list.Version, HaruListVersion)
+	}
+
+	return cfg.restoreAll(list.Anime)
+}
+
+// Writes the whole list as CSV with a header row. Unknown dates are left empty
+func (cfg DBConfig) ExportCSV(w io.Writer) error {
+	anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(csvColumns); err != nil {
+		return err
+	}
+	for _, a := range anime {
+		err := out.Write([]string{
+			strconv.FormatInt(a.ID, 10),
+			a.Title,
+			a.Completion,
+			strconv.FormatInt(a.Watchedepisodes, 10),
+			strconv.FormatInt(a.Score, 10),
+			strconv.FormatInt(a.Timeswatched, 10),
+			a.Startdate.String,
+			a.Finishdate.String,
+			a.Updateddate,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// Restores a list exported with ExportCSV, overwriting entries already in the list. Unknown columns are ignored, and missing numbers count as 0
+func (cfg DBConfig) ImportCSV(data []byte) error {
+	in := csv.NewReader(strings.NewReader(string(data)))
+	in.FieldsPerRecord = -1
+	records, err := in.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("empty csv file")
+	}
+
+	// Spreadsheets often save with a byte order mark at the start
+	records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"id", "title", "completion"} {
+		if _, ok := columns[required]; !ok {
+			return fmt.Errorf("csv file has no %s column", required)
+		}
+	}
+
+	list := []types.HaruAnime{}
+	for n, record := range records[1:] {
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		number := func(name string) (int64, error) {
+			raw := field(name)
+			if raw == "" {
+				return 0, nil
+			}
+			i, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				// Counting the header as row 1, like a spreadsheet does
+				return 0, fmt.Errorf("row %d: invalid %s %q", n+2, name, raw)
+			}
+			return i, nil
+		}
+
+		h := types.HaruAnime{Title: field("title"), Completion: field("completion"), UpdatedDate: field("updated_date")}
+		if start := field("start_date"); start != "" {
+			h.StartDate = &start
+		}
+		if finish := field("finish_date"); finish != "" {
+			h.FinishDate = &finish
+		}
+		if h.ID, err = number("id"); err != nil {
+			return err
+		}
+		if h.WatchedEpisodes, err = number("watched_episodes"); err != nil {
+			return err
+		}
+		if h.Score, err = number("score"); err != nil {
+			return err
+		}
+		if h.TimesWatched, err = number("times_watched"); err != nil {
+			return err
+		}
+		list = append(list, h)
+	}
+
+	return cfg.restoreAll(list)
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// How long cached anime details are trusted before being fetched again
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// Saves anime details from Jikan, replacing whatever was cached for it before
+func (cfg DBConfig) CacheAnime(anime types.AnimeData) error {
+	data, err := json.Marshal(anime)
+	if err != nil {
+		return err
+	}
+
+	genres := []string{}
+	for _, g := range anime.Genres {
+		genres = append(genres, g.Name)
+	}
+	studios := []string{}
+	for _, s := range anime.Studios {
+		studios = append(studios, s.Name)
+	}
+
+	return cfg.DB.UpsertMetadata(cfg.Ctx, database.UpsertMetadataParams{
+		ID:            int64(anime.MalID),
+		Title:         anime.Title,
+		Titleenglish:  anime.TitleEnglish,
+		Titlejapanese: anime.TitleJapanese,
+		Synonyms:      strings.Join(anime.TitleSynonyms, "\n"),
+		Synopsis:      anime.Synopsis,
+		Type:          anime.Type,
+		Episodes:      int64(anime.Episodes),
+		Status:        anime.Status,
+		Airing:        anime.Airing,
+		Airedfrom:     sql.NullString{String: anime.Aired.From, Valid: anime.Aired.From != ""},
+		Airedto:       sql.NullString{String: anime.Aired.To, Valid: anime.Aired.To != ""},
+		Genres:        strings.Join(genres, ", "),
+		Studios:       strings.Join(studios, ", "),
+		Imageurl:      anime.Images.Jpg.ImageURL,
+		Data:          string(data),
+		Fetchedat:     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Cached details for an anime and when they were fetched. Returns sql.ErrNoRows if it was never cached
+func (cfg DBConfig) CachedAnime(id int64) (types.AnimeData, time.Time, error) {
+	row, err := cfg.DB.GetMetadata(cfg.Ctx, id)
+	if err != nil {
+		return types.AnimeData{}, time.Time{}, err
+	}
+	return decodeMetadata(row)
+}
+
+// Every cached anime, keyed by MAL ID
+func (cfg DBConfig) AllCachedAnime() (map[int64]types.AnimeData, error) {
+	rows, err := cfg.DB.GetAllMetadata(cfg.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cached := map[int64]types.AnimeData{}
+	for _, row := range rows {
+		anime, _, err := decodeMetadata(row)
+		if err != nil {
+			return nil, err
+		}
+		cached[row.ID] = anime
+	}
+	return cached, nil
+}
+
+// Escapes LIKE's wildcards, so they only match themselves in a pattern using ESCAPE '\'
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Cached anime with a title or synonym containing query, for searching while offline. Goes through the search index when there is one
+func (cfg DBConfig) SearchCachedAnime(query string, limit int) ([]types.AnimeData, error) {
+	if cfg.SearchIndex {
+		return cfg.SearchIndexedCache(query, limit)
+	}
+
+	rows, err := cfg.DB.SearchMetadata(cfg.Ctx, database.SearchMetadataParams{
+		Pattern:    "%" + likeEscaper.Replace(query) + "%",
+		MaxResults: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadataRows(rows)
+}
+
+// The highest scored cached anime, standing in for the top anime while offline
+func (cfg DBConfig) TopCachedAnime(limit int) ([]types.AnimeData, error) {
+	rows, err := cfg.DB.GetTopMetadata(cfg.Ctx, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadataRows(rows)
+}
+
+// Every other title (English, Japanese and synonyms) cached for each anime, for searching the list by more than its main title
+func (cfg DBConfig) CachedTitles() (map[int64][]string, error) {
+	rows, err := cfg.DB.GetMetadataTitles(cfg.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := map[int64][]string{}
+	for _, row := range rows {
+		all := []string{row.Titleenglish, row.Titlejapanese}
+		if row.Synonyms != "" {
+			all = append(all, strings.Split(row.Synonyms, "\n")...)
+		}
+		for _, t := range all {
+			if t != "" {
+				titles[row.ID] = append(titles[row.ID], t)
+			}
+		}
+	}
+	return titles, nil
+}
+
+func decodeMetadataRows(rows []database.Metadatum) ([]types.AnimeData, error) {
+	anime := []types.AnimeData{}
+	for _, row := range rows {
+		a, _, err := decodeMetadata(row)
+		if err != nil {
+			return nil, err
+		}
+		anime = append(anime, a)
+	}
+	return anime, nil
+}
+
+func decodeMetadata(row database.Metadatum) (types.AnimeData, time.Time, error) {
+	var anime types.AnimeData
+	if err := json.Unmarshal([]byte(row.Data), &anime); err != nil {
+		return types.AnimeData{}, time.Time{}, err
+	}
+
+	fetched, err := time.Parse(time.RFC3339, row.Fetchedat)
+	return anime, fetched, err
+}
+
+// IDs that should be fetched again: anything cached more than ttl ago, plus anything in the list that was never cached
+func (cfg DBConfig) StaleCacheIDs(ttl time.Duration) ([]int64, error) {
+	stale, err := cfg.DB.GetStaleMetadataIDs(cfg.Ctx, time.Now().Add(-ttl).UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+
+	missing, err := cfg.DB.GetAnimeIDsWithoutMetadata(cfg.Ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(stale, missing...), nil
+}
+
+// Removes cached details fetched more than olderThan ago, or everything if olderThan is 0. Returns how many were removed
+func (cfg DBConfig) PruneCache(olderThan time.Duration) (int64, error) {
+	if olderThan == 0 {
+		return cfg.DB.DeleteAllMetadata(cfg.Ctx)
+	}
+	return cfg.DB.DeleteMetadataBefore(cfg.Ctx, time.Now().Add(-olderThan).UTC().Format(time.RFC3339))
+}
@@ -4,53 +4,111 @@ import (
 	"context"
 	"database/sql"
 	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/saubuny/haru/dates"
 	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/jikan"
 	"github.com/saubuny/haru/types"
 )
 
 type DBConfig struct {
-	DB  *database.Queries
-	Ctx context.Context
+	DB   *database.Queries
+	Conn *sql.DB
+	Ctx  context.Context
+
+	// Whether the FTS5 search index is set up, see EnableSearchIndex
+	SearchIndex bool
 }
 
-// TODO: Func for udpating data
+// Opens the database without touching its schema
+func Open(location string) (DBConfig, error) {
+	db, err := sql.Open("sqlite3", location)
+	if err != nil {
+		return DBConfig{}, err
+	}
+
+	// SQLite only allows one writer at a time anyway, and every connection to :memory: would get its own empty database
+	db.SetMaxOpenConns(1)
+
+	return DBConfig{DB: database.New(db), Conn: db, Ctx: context.Background()}, nil
+}
 
-// TODO: input custom db location (like ~/.haru/anime.db)
+// Opens the database and brings its schema up to date with the migrations in fsys
 // TODO: have separate table for manga !!
-func InitDB(schema string, location string) (DBConfig, error) {
-	// db, err := sql.Open("sqlite3", ":memory:")
-	db, err := sql.Open("sqlite3", location)
+func InitDB(migrations fs.FS, location string) (DBConfig, error) {
+	loaded, err := LoadMigrations(migrations)
 	if err != nil {
 		return DBConfig{}, err
 	}
 
-	dbQueries := database.New(db)
-	cfg := DBConfig{DB: dbQueries, Ctx: context.Background()}
+	cfg, err := Open(location)
+	if err != nil {
+		return DBConfig{}, err
+	}
 
-	if _, err := db.ExecContext(cfg.Ctx, schema); err != nil {
+	if err := cfg.Migrate(loaded); err != nil {
+		cfg.Conn.Close()
 		return DBConfig{}, err
 	}
 
 	return cfg, nil
 }
 
-func (cfg DBConfig) UploadToDB(title string, id int, startDate string, completion string) error {
+// Inserts the anime, or overwrites the tracking state of an existing entry with the same ID. The updated date is always set to today
+func (cfg DBConfig) UploadToDB(anime database.Anime) error {
+	anime.Updateddate = time.Now().Format("2006-01-02") // sqlc made the naming weird >:(
+	return cfg.saveAnime(anime)
+}
+
+// Saves every row in one transaction, so a write failing part way through doesn't leave an import half done. Updated dates are set to today like
+// UploadToDB, unless keepUpdated is set
+func (cfg DBConfig) saveAll(rows []database.Anime, keepUpdated bool) error {
+	tx, err := cfg.Conn.BeginTx(cfg.Ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txCfg := cfg
+	txCfg.DB = cfg.DB.WithTx(tx)
+
+	today := time.Now().Format("2006-01-02")
+	for _, anime := range rows {
+		if !keepUpdated {
+			anime.Updateddate = today
+		}
+		if err := txCfg.saveAnime(anime); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Like UploadToDB, but keeps the updated date as it is
+func (cfg DBConfig) saveAnime(anime database.Anime) error {
 	// Check if ID already exists, create new anime if it does
-	_, err := cfg.DB.GetAnime(cfg.Ctx, int64(id))
+	_, err := cfg.DB.GetAnime(cfg.Ctx, anime.ID)
 	if err == sql.ErrNoRows {
 		_, err = cfg.DB.CreateAnime(cfg.Ctx, database.CreateAnimeParams{
-			ID:          int64(id),
-			Title:       title,
-			Startdate:   startDate,
-			Updateddate: time.Now().Format("2006-01-02"), // sqlc made the naming weird >:(
-			Completion:  completion,
+			ID:              anime.ID,
+			Title:           anime.Title,
+			Startdate:       anime.Startdate,
+			Updateddate:     anime.Updateddate,
+			Completion:      anime.Completion,
+			Watchedepisodes: anime.Watchedepisodes,
+			Score:           anime.Score,
+			Finishdate:      anime.Finishdate,
+			Timeswatched:    anime.Timeswatched,
 		})
 
-		return nil
+		return err
 	}
 
 	if err != nil {
@@ -59,10 +117,14 @@ func (cfg DBConfig) UploadToDB(title string, id int, startDate string, completio
 
 	// The current anime being imported should overwrite the old one !!
 	err = cfg.DB.UpdateAnime(cfg.Ctx, database.UpdateAnimeParams{
-		Startdate:   startDate,
-		Updateddate: time.Now().Format("2006-01-02"),
-		Completion:  completion,
-		ID:          int64(id),
+		Startdate:       anime.Startdate,
+		Updateddate:     anime.Updateddate,
+		Completion:      anime.Completion,
+		Watchedepisodes: anime.Watchedepisodes,
+		Score:           anime.Score,
+		Finishdate:      anime.Finishdate,
+		Timeswatched:    anime.Timeswatched,
+		ID:              anime.ID,
 	})
 
 	if err != nil {
@@ -72,8 +134,26 @@ func (cfg DBConfig) UploadToDB(title string, id int, startDate string, completio
 	return nil
 }
 
-// Kitsu also exports in the MAL format
-// Kitsu needs to make an HTTP request for EVERY entry. we can use a COOL BUBBLES PROGRESS BAR FOR THAT :fire:
+// Adds a single anime to the list. If it is already there, only the completion and start date change so no progress is lost
+func (cfg DBConfig) AddAnime(id int64, title string, completion string, startDate sql.NullString) error {
+	existing, err := cfg.DB.GetAnime(cfg.Ctx, id)
+	if err == sql.ErrNoRows {
+		return cfg.UploadToDB(database.Anime{
+			ID:         id,
+			Title:      title,
+			Startdate:  startDate,
+			Completion: completion,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	existing.Completion = completion
+	existing.Startdate = startDate
+	return cfg.UploadToDB(existing)
+}
+
 func (cfg DBConfig) ImportMAL(malXml []byte) error {
 	var animeList types.Myanimelist
 	if err := xml.Unmarshal(malXml, &animeList); err != nil {
@@ -82,22 +162,106 @@ func (cfg DBConfig) ImportMAL(malXml []byte) error {
 
 	log.Printf("Importing Anime...")
 	for _, anime := range animeList.Anime {
-		id, _ := strconv.Atoi(anime.SeriesAnimedbID)
-
-		// Different platforms use different naming
-		completion := anime.MyStatus
-		if anime.MyStatus == "Plan to Watch" {
-			completion = types.PlanToWatch
-		} else if anime.MyStatus == "On-Hold" {
-			completion = types.OnHold
+		cfg.UploadToDB(malAnime(anime))
+	}
+
+	return nil
+}
+
+// Converts an entry from a MAL export into a row
+func malAnime(anime types.MyanimelistAnime) database.Anime {
+	id, _ := strconv.Atoi(anime.SeriesAnimedbID)
+
+	// Different platforms use different naming
+	completion := anime.MyStatus
+	if anime.MyStatus == "Plan to Watch" {
+		completion = types.PlanToWatch
+	} else if anime.MyStatus == "On-Hold" {
+		completion = types.OnHold
+	}
+
+	// Missing or malformed numbers just mean nothing was tracked
+	episodes, _ := strconv.Atoi(anime.MyWatchedEpisodes)
+	score, _ := strconv.Atoi(anime.MyScore)
+	timesWatched, _ := strconv.Atoi(anime.MyTimesWatched)
+
+	return database.Anime{
+		ID:              int64(id),
+		Title:           anime.SeriesTitle,
+		Startdate:       dates.Normalise(anime.MyStartDate),
+		Completion:      completion,
+		Watchedepisodes: int64(episodes),
+		Score:           int64(score),
+		Finishdate:      dates.Normalise(anime.MyFinishDate),
+		Timeswatched:    int64(timesWatched),
+	}
+}
+
+// Hianime only exports a name and a link for each entry, so every entry has to be matched to a MAL ID through the resolver. Returns the entries that could not be matched.
+// Entries that can't be looked up because we're offline are queued for RetryPendingLookups instead
+func (cfg DBConfig) ImportHianime(hiXml []byte, resolve Resolver) ([]string, error) {
+	var animeList types.HiAnimeList
+	if err := xml.Unmarshal(hiXml, &animeList); err != nil {
+		return nil, err
+	}
+
+	unresolved := []string{}
+
+	log.Printf("Importing Anime...")
+	for _, folder := range animeList.Folder {
+		completion, ok := hianimeCompletion(folder.Name)
+		if !ok {
+			return nil, fmt.Errorf("unknown hianime folder %q", folder.Name)
 		}
 
-		cfg.UploadToDB(anime.SeriesTitle, id, anime.MyStartDate, completion)
+		for _, item := range folder.Data.Item {
+			title := hianimeSlugTitle(item.Link)
+			if title == "" {
+				title = item.Name
+			}
+
+			id, ok, err := resolve(title)
+			if errors.Is(err, jikan.ErrOffline) {
+				log.Printf("Offline, %q will be looked up later", item.Name)
+				if err := cfg.queueLookup(title, item.Name, item.Link, database.Anime{Completion: completion}); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				log.Printf("Could not resolve %q: %v", item.Name, err)
+			}
+			if err != nil || !ok {
+				unresolved = append(unresolved, fmt.Sprintf("%s (%s)", item.Name, item.Link))
+				continue
+			}
+
+			err = cfg.UploadToDB(database.Anime{
+				ID:         int64(id),
+				Title:      item.Name,
+				Completion: completion,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	return nil
+	return unresolved, nil
 }
 
-func (cfg DBConfig) ImportHianime(hiXml []byte) error {
-	return nil
+func hianimeCompletion(folder string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(folder)) {
+	case "watching":
+		return types.Watching, true
+	case "on-hold":
+		return types.OnHold, true
+	case "plan to watch":
+		return types.PlanToWatch, true
+	case "dropped":
+		return types.Dropped, true
+	case "completed":
+		return types.Completed, true
+	}
+	return "", false
 }
@@ -1,29 +1,51 @@
 package db
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/types"
 )
 
 // This project only really needs to test the importing logic for the database
-func TestImportMal1(t *testing.T) {
-	// Create test database in memory
-	migrations := `CREATE TABLE IF NOT EXISTS anime (
-    id INTEGER PRIMARY KEY,
-    title TEXT NOT NULL,
-    startDate TEXT NOT NULL,
-    updatedDate TEXT NOT NULL,
-    completion TEXT NOT NULL
-);`
 
-	cfg, err := InitDB(migrations, ":memory:")
+func loadTestMigrations(t *testing.T) []Migration {
+	migrations, err := LoadMigrations(os.DirFS("../sql/schema"))
 	if err != nil {
 		t.Fatal(err)
 	}
+	return migrations
+}
+
+// Creates test database in memory, using the real migrations
+func newTestDB(t *testing.T) DBConfig {
+	cfg, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Migrate(loadTestMigrations(t)); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestImportMal1(t *testing.T) {
+	cfg := newTestDB(t)
 
 	// Short list of Mal XML
 	xml1 := `<?xml version="1.0" encoding="UTF-8" ?>
@@ -72,7 +94,7 @@ func TestImportMal1(t *testing.T) {
             </anime>
         </myanimelist> `
 	// Import to both to DB
-	err = cfg.ImportMAL([]byte(xml1))
+	err := cfg.ImportMAL([]byte(xml1))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -87,28 +109,28 @@ func TestImportMal1(t *testing.T) {
 		{
 			ID:          21,
 			Title:       "One Piece",
-			Startdate:   "2024-11-13",
+			Startdate:   sql.NullString{String: "2024-11-13", Valid: true},
 			Updateddate: time.Now().Format("2006-01-02"),
 			Completion:  "Watching",
 		},
 		{
 			ID:          66,
 			Title:       "Azumanga Daiou The Animation",
-			Startdate:   "0000-00-00",
+			Startdate:   sql.NullString{},
 			Updateddate: time.Now().Format("2006-01-02"),
 			Completion:  "Plan To Watch",
 		},
 		{
 			ID:          853,
 			Title:       "Ouran Koukou Host Club",
-			Startdate:   "2022-01-07",
+			Startdate:   sql.NullString{String: "2022-01-07", Valid: true},
 			Updateddate: time.Now().Format("2006-01-02"),
 			Completion:  "Dropped",
 		},
 		{
 			ID:          30276,
 			Title:       "One Punch Man",
-			Startdate:   "2020-02-05",
+			Startdate:   sql.NullString{String: "2020-02-05", Valid: true},
 			Updateddate: time.Now().Format("2006-01-02"),
 			Completion:  "Completed",
 		},
@@ -120,3 +142,1178 @@ func TestImportMal1(t *testing.T) {
 		t.Fatalf("dbState differs from expected input:\n%#v\n%#v\n", dbState, expected)
 	}
 }
+
+func TestImportHianime(t *testing.T) {
+	cfg := newTestDB(t)
+
+	hiXml := `<?xml version="1.0" encoding="UTF-8" ?>
+        <list>
+            <folder>
+                <name>Watching</name>
+                <data>
+                    <item>
+                        <name>One Piece</name>
+                        <link>https://hianime.to/watch/one-piece-100</link>
+                    </item>
+                </data>
+            </folder>
+            <folder>
+                <name>On-Hold</name>
+                <data>
+                    <item>
+                        <name>Kaguya-sama: Love is War</name>
+                        <link>https://hianime.to/kaguyasama-love-is-war-82</link>
+                    </item>
+                </data>
+            </folder>
+            <folder>
+                <name>Plan to watch</name>
+                <data>
+                    <item>
+                        <name>Some Obscure Short</name>
+                        <link>https://hianime.to/some-obscure-short-9999</link>
+                    </item>
+                </data>
+            </folder>
+        </list>`
+
+	// Fake resolver so the test doesn't touch the network
+	known := map[string]int{
+		"one piece":              21,
+		"kaguyasama love is war": 37999,
+	}
+	resolve := func(title string) (int, bool, error) {
+		id, ok := known[title]
+		return id, ok, nil
+	}
+
+	unresolved, err := cfg.ImportHianime([]byte(hiXml), resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedUnresolved := []string{"Some Obscure Short (https://hianime.to/some-obscure-short-9999)"}
+	if !reflect.DeepEqual(unresolved, expectedUnresolved) {
+		t.Fatalf("unresolved differs from expected:\n%#v\n%#v\n", unresolved, expectedUnresolved)
+	}
+
+	expected := []database.Anime{
+		{
+			ID:          21,
+			Title:       "One Piece",
+			Updateddate: time.Now().Format("2006-01-02"),
+			Completion:  "Watching",
+		},
+		{
+			ID:          37999,
+			Title:       "Kaguya-sama: Love is War",
+			Updateddate: time.Now().Format("2006-01-02"),
+			Completion:  "On Hold",
+		},
+	}
+
+	dbState, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if !reflect.DeepEqual(dbState, expected) {
+		t.Fatalf("dbState differs from expected input:\n%#v\n%#v\n", dbState, expected)
+	}
+}
+
+func TestImportHianimeOffline(t *testing.T) {
+	cfg := newTestDB(t)
+
+	hiXml := `<?xml version="1.0" encoding="UTF-8" ?>
+        <list>
+            <folder>
+                <name>Completed</name>
+                <data>
+                    <item>
+                        <name>One Piece</name>
+                        <link>https://hianime.to/watch/one-piece-100</link>
+                    </item>
+                    <item>
+                        <name>Some Obscure Short</name>
+                        <link>https://hianime.to/some-obscure-short-9999</link>
+                    </item>
+                </data>
+            </folder>
+        </list>`
+
+	offline := func(title string) (int, bool, error) {
+		return 0, false, fmt.Errorf("jikan /anime: %w", jikan.ErrOffline)
+	}
+
+	unresolved, err := cfg.ImportHianime([]byte(hiXml), offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 0 {
+		t.Fatalf("expected offline entries to be queued, not unresolved: %v", unresolved)
+	}
+	if count, err := cfg.PendingLookupCount(); err != nil || count != 2 {
+		t.Fatalf("expected 2 pending lookups, got %d (%v)", count, err)
+	}
+
+	// Still offline, so nothing changes
+	if added, _, err := cfg.RetryPendingLookups(offline); err != nil || added != 0 {
+		t.Fatalf("expected nothing to be added while offline, got %d (%v)", added, err)
+	}
+
+	online := func(title string) (int, bool, error) {
+		return 21, title == "one piece", nil
+	}
+
+	added, unresolved, err := cfg.RetryPendingLookups(online)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 || !reflect.DeepEqual(unresolved, []string{"Some Obscure Short (https://hianime.to/some-obscure-short-9999)"}) {
+		t.Fatalf("unexpected retry result: %d added, unresolved %v", added, unresolved)
+	}
+	if count, err := cfg.PendingLookupCount(); err != nil || count != 0 {
+		t.Fatalf("expected the queue to be empty, got %d (%v)", count, err)
+	}
+
+	anime, err := cfg.DB.GetAnime(cfg.Ctx, 21)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.Title != "One Piece" || anime.Completion != types.Completed {
+		t.Fatalf("unexpected anime %#v", anime)
+	}
+}
+
+func TestBestMatch(t *testing.T) {
+	results := []types.AnimeData{
+		{MalID: 1, Title: "Cowboy Bebop"},
+		{MalID: 5, Title: "Cowboy Bebop: Tengoku no Tobira", TitleEnglish: "Cowboy Bebop: The Movie"},
+	}
+
+	id, score := bestMatch(hianimeSlugTitle("https://hianime.to/cowboy-bebop-the-movie-123"), results)
+	if id != 5 || score < DefaultResolveThreshold {
+		t.Fatalf("expected a confident match for 5, got %d (%v)", id, score)
+	}
+
+	_, score = bestMatch("completely unrelated", results)
+	if score >= DefaultResolveThreshold {
+		t.Fatalf("expected no confident match, got %v", score)
+	}
+}
+
+func TestImportMalTracking(t *testing.T) {
+	// Database from before migrations were versioned, without any tracking columns
+	cfg, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.Conn.ExecContext(cfg.Ctx, `CREATE TABLE anime (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    startDate TEXT NOT NULL,
+    updatedDate TEXT NOT NULL,
+    completion TEXT NOT NULL
+);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Migrate(loadTestMigrations(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	malXml := `<?xml version="1.0" encoding="UTF-8" ?>
+        <myanimelist>
+            <anime>
+                <series_animedb_id>1</series_animedb_id>
+                <series_title><![CDATA[Cowboy Bebop]]></series_title>
+                <my_watched_episodes>26</my_watched_episodes>
+                <my_start_date>2021-03-01</my_start_date>
+                <my_finish_date>2021-03-20</my_finish_date>
+                <my_score>9</my_score>
+                <my_status>Completed</my_status>
+                <my_times_watched>2</my_times_watched>
+            </anime>
+            <anime>
+                <series_animedb_id>21</series_animedb_id>
+                <series_title><![CDATA[One Piece]]></series_title>
+                <my_watched_episodes>140</my_watched_episodes>
+                <my_start_date>2021-07-06</my_start_date>
+                <my_finish_date>0000-00-00</my_finish_date>
+                <my_score>0</my_score>
+                <my_status>On-Hold</my_status>
+                <my_times_watched>0</my_times_watched>
+            </anime>
+        </myanimelist>`
+
+	if err := cfg.ImportMAL([]byte(malXml)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []database.Anime{
+		{
+			ID:              1,
+			Title:           "Cowboy Bebop",
+			Startdate:       sql.NullString{String: "2021-03-01", Valid: true},
+			Updateddate:     time.Now().Format("2006-01-02"),
+			Completion:      "Completed",
+			Watchedepisodes: 26,
+			Score:           9,
+			Finishdate:      sql.NullString{String: "2021-03-20", Valid: true},
+			Timeswatched:    2,
+		},
+		{
+			ID:              21,
+			Title:           "One Piece",
+			Startdate:       sql.NullString{String: "2021-07-06", Valid: true},
+			Updateddate:     time.Now().Format("2006-01-02"),
+			Completion:      "On Hold",
+			Watchedepisodes: 140,
+		},
+	}
+
+	dbState, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dbState, expected) {
+		t.Fatalf("dbState differs from expected input:\n%#v\n%#v\n", dbState, expected)
+	}
+}
+
+func TestExportMAL(t *testing.T) {
+	cfg := newTestDB(t)
+
+	list := []database.Anime{
+		{ID: 1, Title: "Cowboy Bebop", Completion: types.Completed, Watchedepisodes: 26, Score: 9, Timeswatched: 2,
+			Startdate: sql.NullString{String: "2021-03-01", Valid: true}, Finishdate: sql.NullString{String: "2021-03-20", Valid: true}},
+		{ID: 21, Title: "One Piece", Completion: types.OnHold, Watchedepisodes: 140, Startdate: sql.NullString{String: "2021-07", Valid: true}},
+		{ID: 5114, Title: "Fullmetal Alchemist: Brotherhood", Completion: types.Watching, Watchedepisodes: 3, Startdate: sql.NullString{String: "2023", Valid: true}},
+		{ID: 820, Title: "Ginga Eiyuu Densetsu", Completion: types.PlanToWatch},
+		{ID: 30, Title: "Tom & Jerry <Kids>", Completion: types.Dropped, Watchedepisodes: 1, Score: 3},
+	}
+	for _, a := range list {
+		if err := cfg.UploadToDB(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := cfg.ExportMAL(&out); err != nil {
+		t.Fatal(err)
+	}
+	exported := out.String()
+
+	for _, expected := range []string{
+		"<user_export_type>1</user_export_type>",
+		"<user_total_anime>5</user_total_anime>",
+		"<user_total_onhold>1</user_total_onhold>",
+		"<user_total_plantowatch>1</user_total_plantowatch>",
+		"<my_status>Plan to Watch</my_status>",
+		"<my_status>On-Hold</my_status>",
+		"<my_start_date>2021-07-00</my_start_date>",
+		"<my_start_date>2023-00-00</my_start_date>",
+		"<my_finish_date>0000-00-00</my_finish_date>",
+		"<update_on_import>1</update_on_import>",
+		"Tom &amp; Jerry &lt;Kids&gt;",
+	} {
+		if !strings.Contains(exported, expected) {
+			t.Errorf("expected export to contain %q:\n%s", expected, exported)
+		}
+	}
+
+	// Importing the export into a fresh database gets the same list back
+	fresh := newTestDB(t)
+	if err := fresh.ImportMAL(out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	expected, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fresh.DB.GetAllAnime(fresh.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("round trip differs:\n%#v\n%#v\n", got, expected)
+	}
+}
+
+// A list with everything filled in at least once, updated on different days
+func seedBackupList(t *testing.T, cfg DBConfig) []database.Anime {
+	list := []database.Anime{
+		{ID: 1, Title: "Cowboy Bebop", Completion: types.Completed, Watchedepisodes: 26, Score: 9, Timeswatched: 2, Updateddate: "2021-03-20",
+			Startdate: sql.NullString{String: "2021-03-01", Valid: true}, Finishdate: sql.NullString{String: "2021-03-20", Valid: true}},
+		{ID: 21, Title: "One Piece", Completion: types.OnHold, Watchedepisodes: 140, Updateddate: "2022-01-05", Startdate: sql.NullString{String: "2021-07", Valid: true}},
+		{ID: 30, Title: `Tom, "Jerry" & friends`, Completion: types.Dropped, Watchedepisodes: 1, Score: 3, Updateddate: "2024-10-18"},
+	}
+	for _, a := range list {
+		if err := cfg.saveAnime(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	saved, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved
+}
+
+func TestExportJSON(t *testing.T) {
+	cfg := newTestDB(t)
+	expected := seedBackupList(t, cfg)
+
+	var out bytes.Buffer
+	if err := cfg.ExportJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"version": 1`) || !strings.Contains(out.String(), `"finish_date": null`) {
+		t.Fatalf("unexpected export:\n%s", out.String())
+	}
+
+	fresh := newTestDB(t)
+	if err := fresh.ImportJSON(out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fresh.DB.GetAllAnime(fresh.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("round trip differs:\n%#v\n%#v\n", got, expected)
+	}
+
+	// Fields that aren't there yet default, and unknown ones from later versions are ignored
+	minimal := `{"version": 1, "anime": [{"id": 5114, "title": "FMA", "completion": "Watching", "rating": "PG-13"}]}`
+	if err := fresh.ImportJSON([]byte(minimal)); err != nil {
+		t.Fatal(err)
+	}
+	anime, err := fresh.DB.GetAnime(fresh.Ctx, 5114)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.Updateddate != time.Now().Format("2006-01-02") || anime.Startdate.Valid {
+		t.Fatalf("unexpected defaults %#v", anime)
+	}
+
+	for _, bad := range []string{
+		`{"anime": []}`,
+		`{"version": 2, "anime": []}`,
+		`{"version": 1, "anime": [{"id": 1, "title": "A", "completion": "Watching"}, {"id": 2, "title": "B", "completion": "Finished"}]}`,
+		`{"version": 1, "anime": [{"id": 1, "title": "A", "completion": "Watching", "start_date": "yesterday"}]}`,
+		`{"version": 1, "anime": [{"id": 1, "title": "A", "completion": "Watching", "score": 11}]}`,
+	} {
+		if err := newTestDB(t).ImportJSON([]byte(bad)); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+
+	// Nothing is saved when any entry is bad
+	partial := newTestDB(t)
+	partial.ImportJSON([]byte(`{"version": 1, "anime": [{"id": 1, "title": "A", "completion": "Watching"}, {"id": 0, "title": "B", "completion": "Watching"}]}`))
+	if all, _ := partial.DB.GetAllAnime(partial.Ctx); len(all) != 0 {
+		t.Fatalf("expected nothing imported, got %#v", all)
+	}
+
+	// Or when saving one fails partway through
+	failing := newTestDB(t)
+	if _, err := failing.Conn.Exec(`CREATE TRIGGER fail_second BEFORE INSERT ON anime WHEN NEW.id = 2 BEGIN SELECT RAISE(ABORT, 'disk full'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if err := failing.ImportJSON([]byte(`{"version": 1, "anime": [{"id": 1, "title": "A", "completion": "Watching"}, {"id": 2, "title": "B", "completion": "Watching"}]}`)); err == nil {
+		t.Fatal("expected the failed write to be returned")
+	}
+	if all, _ := failing.DB.GetAllAnime(failing.Ctx); len(all) != 0 {
+		t.Fatalf("expected the entries saved before the failure to be rolled back, got %#v", all)
+	}
+}
+
+// Checks a decoded JSON value against the parts of JSON Schema that schema/haru-list.v1.json uses
+func checkSchema(schema map[string]any, value any, path string) []string {
+	problems := []string{}
+
+	allowed := []any{schema["type"]}
+	if list, ok := schema["type"].([]any); ok {
+		allowed = list
+	}
+	if schema["type"] != nil {
+		matched := false
+		for _, want := range allowed {
+			switch v := value.(type) {
+			case nil:
+				matched = matched || want == "null"
+			case string:
+				matched = matched || want == "string"
+			case bool:
+				matched = matched || want == "boolean"
+			case float64:
+				matched = matched || want == "number" || (want == "integer" && v == float64(int64(v)))
+			case []any:
+				matched = matched || want == "array"
+			case map[string]any:
+				matched = matched || want == "object"
+			}
+		}
+		if !matched {
+			return append(problems, fmt.Sprintf("%s: %v isn't %v", path, value, schema["type"]))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			found = found || e == value
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: %v isn't one of %v", path, value, enum))
+		}
+	}
+	if n, ok := value.(float64); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && n < minimum {
+			problems = append(problems, fmt.Sprintf("%s: %v is under %v", path, n, minimum))
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && n > maximum {
+			problems = append(problems, fmt.Sprintf("%s: %v is over %v", path, n, maximum))
+		}
+	}
+	if s, ok := value.(string); ok {
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(s) {
+			problems = append(problems, fmt.Sprintf("%s: %q doesn't match %s", path, s, pattern))
+		}
+	}
+
+	if object, ok := value.(map[string]any); ok {
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := object[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", path, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, v := range object {
+			if property, ok := properties[name].(map[string]any); ok {
+				problems = append(problems, checkSchema(property, v, path+"."+name)...)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]any); ok {
+		for i, v := range value.([]any) {
+			problems = append(problems, checkSchema(items, v, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return problems
+}
+
+func TestExportMatchesSchema(t *testing.T) {
+	raw, err := os.ReadFile("../schema/haru-list.v1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestDB(t)
+	seedBackupList(t, cfg)
+	var out bytes.Buffer
+	if err := cfg.ExportJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var export any
+	if err := json.Unmarshal(out.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	if problems := checkSchema(schema, export, "$"); len(problems) > 0 {
+		t.Fatalf("export doesn't match the schema:\n%s", strings.Join(problems, "\n"))
+	}
+
+	// Making sure the check can fail at all
+	bad := `{"version": 2, "anime": [{"id": 0, "title": "A", "completion": "Finished", "score": 11, "start_date": "yesterday"}]}`
+	if err := json.Unmarshal([]byte(bad), &export); err != nil {
+		t.Fatal(err)
+	}
+	if problems := checkSchema(schema, export, "$"); len(problems) != 5 {
+		t.Fatalf("expected 5 problems, got %q", problems)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	cfg := newTestDB(t)
+	expected := seedBackupList(t, cfg)
+
+	var out bytes.Buffer
+	if err := cfg.ExportCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || lines[0] != "id,title,completion,watched_episodes,score,times_watched,start_date,finish_date,updated_date" {
+		t.Fatalf("unexpected export:\n%s", out.String())
+	}
+
+	fresh := newTestDB(t)
+	if err := fresh.ImportCSV(out.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fresh.DB.GetAllAnime(fresh.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("round trip differs:\n%#v\n%#v\n", got, expected)
+	}
+
+	// Columns can be in any order, and only id, title and completion are needed
+	reordered := "\ufeffCompletion,Title,ID,Notes\nPlan To Watch,Ginga Eiyuu Densetsu,820,someday\n"
+	if err := fresh.ImportCSV([]byte(reordered)); err != nil {
+		t.Fatal(err)
+	}
+	if anime, err := fresh.DB.GetAnime(fresh.Ctx, 820); err != nil || anime.Completion != types.PlanToWatch {
+		t.Fatalf("unexpected anime %#v (%v)", anime, err)
+	}
+
+	for _, bad := range []string{
+		"",
+		"id,title\n1,A\n",
+		"id,title,completion,score\n1,A,Watching,nine\n",
+		"id,title,completion\nabc,A,Watching\n",
+	} {
+		if err := newTestDB(t).ImportCSV([]byte(bad)); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestImportAniList(t *testing.T) {
+	cfg := newTestDB(t)
+
+	// Trimmed down from a real export, with scores out of 100
+	aniList := `{"data": {"MediaListCollection": {
+		"user": {"name": "haru", "mediaListOptions": {"scoreFormat": "POINT_100"}},
+		"lists": [
+			{"name": "Watching", "isCustomList": false, "entries": [
+				{"status": "CURRENT", "score": 0, "progress": 140, "repeat": 0,
+					"startedAt": {"year": 2021, "month": 7, "day": 6}, "completedAt": {"year": null, "month": null, "day": null},
+					"media": {"id": 21, "idMal": 21, "type": "ANIME", "title": {"romaji": "ONE PIECE", "english": "One Piece"}}},
+				{"status": "REPEATING", "score": 95, "progress": 3, "repeat": 1,
+					"startedAt": {"year": 2023, "month": null, "day": null}, "completedAt": {},
+					"media": {"id": 5114, "idMal": 5114, "type": "ANIME", "title": {"romaji": "Hagane no Renkinjutsushi: FULLMETAL ALCHEMIST"}}}
+			]},
+			{"name": "Completed", "isCustomList": false, "entries": [
+				{"status": "COMPLETED", "score": 88, "progress": 26, "repeat": 2,
+					"startedAt": {"year": 2021, "month": 3, "day": 1}, "completedAt": {"year": 2021, "month": 3, "day": 20},
+					"media": {"id": 1, "idMal": 1, "type": "ANIME", "title": {"romaji": "Cowboy Bebop"}}}
+			]},
+			{"name": "Paused", "isCustomList": false, "entries": [
+				{"status": "PAUSED", "score": 4, "progress": 1, "media": {"id": 2, "idMal": 30, "type": "ANIME", "title": {"romaji": "Shinseiki Evangelion"}}}
+			]},
+			{"name": "Dropped", "isCustomList": false, "entries": [
+				{"status": "DROPPED", "score": 30, "progress": 2, "media": {"id": 3, "idMal": 31, "type": "ANIME", "title": {"english": "Evangelion: Death"}}}
+			]},
+			{"name": "Planning", "isCustomList": false, "entries": [
+				{"status": "PLANNING", "media": {"id": 820, "idMal": 820, "type": "ANIME", "title": {"romaji": "Ginga Eiyuu Densetsu"}}},
+				{"status": "PLANNING", "media": {"id": 170000, "idMal": null, "type": "ANIME", "title": {"romaji": "Some Chinese Donghua"}}}
+			]},
+			{"name": "Favourites", "isCustomList": true, "entries": [
+				{"status": "DROPPED", "score": 10, "media": {"id": 1, "idMal": 1, "type": "ANIME", "title": {"romaji": "Cowboy Bebop"}}}
+			]}
+		]
+	}}}`
+
+	unresolved, err := cfg.ImportAniList([]byte(aniList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 1 || unresolved[0] != "Some Chinese Donghua (https://anilist.co/anime/170000)" {
+		t.Fatalf("unexpected unresolved entries %v", unresolved)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	expected := []database.Anime{
+		{ID: 1, Title: "Cowboy Bebop", Updateddate: today, Completion: types.Completed, Watchedepisodes: 26, Score: 9, Timeswatched: 2,
+			Startdate: sql.NullString{String: "2021-03-01", Valid: true}, Finishdate: sql.NullString{String: "2021-03-20", Valid: true}},
+		{ID: 21, Title: "ONE PIECE", Updateddate: today, Completion: types.Watching, Watchedepisodes: 140,
+			Startdate: sql.NullString{String: "2021-07-06", Valid: true}},
+		{ID: 30, Title: "Shinseiki Evangelion", Updateddate: today, Completion: types.OnHold, Watchedepisodes: 1, Score: 1},
+		{ID: 31, Title: "Evangelion: Death", Updateddate: today, Completion: types.Dropped, Watchedepisodes: 2, Score: 3},
+		{ID: 820, Title: "Ginga Eiyuu Densetsu", Updateddate: today, Completion: types.PlanToWatch},
+		{ID: 5114, Title: "Hagane no Renkinjutsushi: FULLMETAL ALCHEMIST", Updateddate: today, Completion: types.Watching, Watchedepisodes: 3, Score: 10, Timeswatched: 1,
+			Startdate: sql.NullString{String: "2023", Valid: true}},
+	}
+
+	dbState, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dbState, expected) {
+		t.Fatalf("dbState differs from expected input:\n%#v\n%#v\n", dbState, expected)
+	}
+
+	// The collection on its own works too, as the GraphQL API returns it
+	bare := newTestDB(t)
+	collection := `{"user": {"mediaListOptions": {"scoreFormat": "POINT_5"}}, "lists": [{"entries": [
+		{"status": "COMPLETED", "score": 4, "progress": 12, "media": {"id": 30, "idMal": 30, "title": {"romaji": "Shinseiki Evangelion"}}}
+	]}]}`
+	if unresolved, err := bare.ImportAniList([]byte(collection)); err != nil || len(unresolved) != 0 {
+		t.Fatalf("unexpected result importing a bare collection: %v, %v", unresolved, err)
+	}
+	anime, err := bare.DB.GetAnime(bare.Ctx, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.Title != "Shinseiki Evangelion" || anime.Completion != types.Completed || anime.Watchedepisodes != 12 || anime.Score != 8 {
+		t.Fatalf("unexpected anime from a bare collection %#v", anime)
+	}
+
+	// A write failing part way through leaves the list as it was
+	failing := newTestDB(t)
+	if _, err := failing.Conn.Exec(`CREATE TRIGGER fail_last BEFORE INSERT ON anime WHEN NEW.id = 820 BEGIN SELECT RAISE(ABORT, 'disk full'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := failing.ImportAniList([]byte(aniList)); err == nil {
+		t.Fatal("expected the failed write to be returned")
+	}
+	if all, _ := failing.DB.GetAllAnime(failing.Ctx); len(all) != 0 {
+		t.Fatalf("expected the entries saved before the failure to be rolled back, got %#v", all)
+	}
+
+	// Unknown statuses are an error
+	if _, err := newTestDB(t).ImportAniList([]byte(`{"lists": [{"entries": [{"status": "WATCHING", "media": {"id": 1, "idMal": 1}}]}]}`)); err == nil {
+		t.Fatal("expected an unknown status to be rejected")
+	}
+}
+
+func TestAniListScore(t *testing.T) {
+	tests := []struct {
+		score    float64
+		format   string
+		expected int64
+	}{
+		{0, "POINT_100", 0},
+		{85, "POINT_100", 9},
+		{3, "POINT_100", 1},
+		{7.5, "POINT_10_DECIMAL", 8},
+		{7, "POINT_10", 7},
+		{4, "POINT_5", 8},
+		{2, "POINT_3", 6},
+	}
+
+	for _, test := range tests {
+		if got := aniListScore(test.score, test.format); got != test.expected {
+			t.Errorf("%v (%s): expected %d, got %d", test.score, test.format, test.expected, got)
+		}
+	}
+}
+
+const kitsuXml = `<?xml version="1.0" encoding="UTF-8" ?>
+	<myanimelist>
+		<myinfo><user_export_type>1</user_export_type></myinfo>
+		<anime>
+			<series_animedb_id>1</series_animedb_id>
+			<series_title><![CDATA[Cowboy Bebop (TV)]]></series_title>
+			<my_watched_episodes>26</my_watched_episodes>
+			<my_score>9</my_score>
+			<my_status>Completed</my_status>
+		</anime>
+		<anime>
+			<series_animedb_id>99999</series_animedb_id>
+			<series_title><![CDATA[One Piece]]></series_title>
+			<my_watched_episodes>140</my_watched_episodes>
+			<my_status>On-Hold</my_status>
+		</anime>
+		<anime>
+			<series_animedb_id>0</series_animedb_id>
+			<series_title><![CDATA[Ginga Eiyuu Densetsu]]></series_title>
+			<my_status>Plan to Watch</my_status>
+		</anime>
+		<anime>
+			<series_animedb_id></series_animedb_id>
+			<series_title><![CDATA[Some Kitsu Only Short]]></series_title>
+			<my_watched_episodes>4</my_watched_episodes>
+			<my_start_date>2024-09-00</my_start_date>
+			<my_score>7</my_score>
+			<my_status>Watching</my_status>
+			<my_times_watched>1</my_times_watched>
+		</anime>
+		<anime>
+			<series_animedb_id>30</series_animedb_id>
+			<series_title><![CDATA[Neon Genesis Evangelion]]></series_title>
+			<my_status>Dropped</my_status>
+		</anime>
+	</myanimelist>`
+
+// Pretends to be Jikan for the anime in kitsuXml
+func kitsuJikan(t *testing.T) *jikan.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/anime/1":
+			fmt.Fprint(w, `{"data": {"mal_id": 1, "title": "Cowboy Bebop", "episodes": 26}}`)
+		case "/anime/30":
+			fmt.Fprint(w, `{"data": {"mal_id": 30, "title": "Shinseiki Evangelion", "episodes": 26}}`)
+		case "/anime":
+			switch r.URL.Query().Get("q") {
+			case "One Piece":
+				fmt.Fprint(w, `{"data": [{"mal_id": 21, "title": "One Piece"}]}`)
+			case "Ginga Eiyuu Densetsu":
+				fmt.Fprint(w, `{"data": [{"mal_id": 820, "title": "Ginga Eiyuu Densetsu"}]}`)
+			default:
+				fmt.Fprint(w, `{"data": [{"mal_id": 5, "title": "Something Else Entirely"}]}`)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status": 404, "message": "Resource does not exist"}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return jikan.New(server.URL)
+}
+
+func TestImportKitsu(t *testing.T) {
+	cfg := newTestDB(t)
+	statePath := filepath.Join(t.TempDir(), "kitsu-import.json")
+
+	kitsu, err := cfg.NewKitsuImport([]byte(kitsuXml), kitsuJikan(t), statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := 0
+	for result := range kitsu.Run(context.Background(), KitsuWorkers) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		results++
+	}
+	if results != 5 || kitsu.Done() != 5 {
+		t.Fatalf("expected 5 results, got %d (%d done)", results, kitsu.Done())
+	}
+	if unresolved := kitsu.Unresolved(); len(unresolved) != 1 || unresolved[0] != "Some Kitsu Only Short" {
+		t.Fatalf("unexpected unresolved entries %v", unresolved)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Fatalf("expected progress to be removed once finished, got %v", err)
+	}
+
+	today := time.Now().Format("2006-01-02")
+	expected := []database.Anime{
+		{ID: 1, Title: "Cowboy Bebop", Updateddate: today, Completion: types.Completed, Watchedepisodes: 26, Score: 9},
+		{ID: 21, Title: "One Piece", Updateddate: today, Completion: types.OnHold, Watchedepisodes: 140},
+		{ID: 30, Title: "Shinseiki Evangelion", Updateddate: today, Completion: types.Dropped},
+		{ID: 820, Title: "Ginga Eiyuu Densetsu", Updateddate: today, Completion: types.PlanToWatch},
+	}
+	dbState, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dbState, expected) {
+		t.Fatalf("dbState differs from expected input:\n%#v\n%#v\n", dbState, expected)
+	}
+
+	// Details that were checked are cached too
+	if cached, _, err := cfg.CachedAnime(1); err != nil || cached.Episodes != 26 {
+		t.Fatalf("expected Cowboy Bebop to be cached, got %#v (%v)", cached, err)
+	}
+}
+
+func TestImportKitsuOffline(t *testing.T) {
+	cfg := newTestDB(t)
+	client := jikan.New("http://localhost:0")
+	client.SetOffline(true)
+
+	kitsu, err := cfg.NewKitsuImport([]byte(kitsuXml), client, filepath.Join(t.TempDir(), "kitsu-import.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for result := range kitsu.Run(context.Background(), KitsuWorkers) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+	}
+
+	// Kitsu's IDs are trusted when they can't be checked, and entries without one wait until we're online
+	anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anime) != 3 || anime[0].Title != "Cowboy Bebop (TV)" {
+		t.Fatalf("expected the 3 entries with IDs, got %#v", anime)
+	}
+	if count, err := cfg.PendingLookupCount(); err != nil || count != 2 {
+		t.Fatalf("expected 2 pending lookups, got %d (%v)", count, err)
+	}
+
+	// Queued entries keep their tracking for when they're looked up
+	online := func(title string) (int, bool, error) {
+		if title == "Some Kitsu Only Short" {
+			return 50000, true, nil
+		}
+		return 0, false, nil
+	}
+	if added, _, err := cfg.RetryPendingLookups(online); err != nil || added != 1 {
+		t.Fatalf("expected 1 lookup to be added, got %d (%v)", added, err)
+	}
+	short, err := cfg.DB.GetAnime(cfg.Ctx, 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := database.Anime{
+		ID:              50000,
+		Title:           "Some Kitsu Only Short",
+		Startdate:       sql.NullString{String: "2024-09", Valid: true},
+		Updateddate:     time.Now().Format("2006-01-02"),
+		Completion:      types.Watching,
+		Watchedepisodes: 4,
+		Score:           7,
+		Timeswatched:    1,
+	}
+	if short != expected {
+		t.Fatalf("queued entry lost its tracking:\n%#v\n%#v", short, expected)
+	}
+}
+
+func TestImportKitsuResume(t *testing.T) {
+	cfg := newTestDB(t)
+	client := kitsuJikan(t)
+	statePath := filepath.Join(t.TempDir(), "kitsu-import.json")
+
+	kitsu, err := cfg.NewKitsuImport([]byte(kitsuXml), client, statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stopping after the first result only lets the lookup already in progress finish
+	ctx, cancel := context.WithCancel(context.Background())
+	results := kitsu.Run(ctx, 1)
+	<-results
+	cancel()
+	for range results {
+	}
+	stoppedAt := kitsu.Done()
+	if stoppedAt == 0 || stoppedAt == kitsu.Total() {
+		t.Fatalf("expected the import to stop partway, got %d of %d", stoppedAt, kitsu.Total())
+	}
+
+	// A different file starts over
+	other, err := cfg.NewKitsuImport([]byte(strings.Replace(kitsuXml, "Dropped", "Completed", 1)), client, statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Done() != 0 {
+		t.Fatalf("expected a different export to start over, got %d done", other.Done())
+	}
+
+	resumed, err := cfg.NewKitsuImport([]byte(kitsuXml), client, statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Done() != stoppedAt {
+		t.Fatalf("expected to carry on from %d, got %d", stoppedAt, resumed.Done())
+	}
+
+	count := 0
+	for result := range resumed.Run(context.Background(), KitsuWorkers) {
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		}
+		count++
+	}
+	if count != kitsu.Total()-stoppedAt || resumed.Done() != kitsu.Total() {
+		t.Fatalf("expected the other %d entries, got %d", kitsu.Total()-stoppedAt, count)
+	}
+	if anime, err := cfg.DB.GetAllAnime(cfg.Ctx); err != nil || len(anime) != 4 {
+		t.Fatalf("expected 4 anime, got %d (%v)", len(anime), err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	migrations := loadTestMigrations(t)
+	cfg := newTestDB(t)
+
+	version, err := cfg.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations) {
+		t.Fatalf("expected schema version %d, got %d", len(migrations), version)
+	}
+
+	// Running again should do nothing
+	if err := cfg.Migrate(migrations); err != nil {
+		t.Fatal(err)
+	}
+
+	// A database from a newer haru must be left alone
+	if _, err := cfg.Conn.ExecContext(cfg.Ctx, "PRAGMA user_version = 999"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Migrate(migrations); err == nil {
+		t.Fatal("expected an error migrating a newer database")
+	}
+}
+
+func TestMigrateRollsBack(t *testing.T) {
+	migrations := loadTestMigrations(t)
+	migrations = append(migrations, Migration{
+		Version: len(migrations) + 1,
+		Name:    "broken",
+		SQL:     "CREATE TABLE half_done (id INTEGER); SELECT * FROM does_not_exist;",
+	})
+
+	cfg, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Migrate(migrations); err == nil {
+		t.Fatal("expected broken migration to fail")
+	}
+
+	version, err := cfg.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != len(migrations)-1 {
+		t.Fatalf("expected schema version %d, got %d", len(migrations)-1, version)
+	}
+
+	var count int
+	cfg.Conn.QueryRowContext(cfg.Ctx, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'").Scan(&count)
+	if count != 0 {
+		t.Fatal("broken migration was not rolled back")
+	}
+}
+
+func TestMigrateNormalisesDates(t *testing.T) {
+	migrations := loadTestMigrations(t)
+	cfg, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop just before dates became nullable, with the placeholders older versions stored as-is
+	if err := cfg.Migrate(migrations[:2]); err != nil {
+		t.Fatal(err)
+	}
+	_, err = cfg.Conn.ExecContext(cfg.Ctx, `INSERT INTO anime (id, title, startDate, updatedDate, completion, finishDate) VALUES
+        (1, 'a', '0000-00-00', '2024-01-01', 'Watching', ''),
+        (2, 'b', '2020-05-00', '2024-01-01', 'Completed', '2021-00-00'),
+        (3, 'c', '2020-05-17', '2024-01-01', 'Completed', '2020-06-01')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.Migrate(migrations); err != nil {
+		t.Fatal(err)
+	}
+
+	anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]sql.NullString{
+		{{}, {}},
+		{{String: "2020-05", Valid: true}, {String: "2021", Valid: true}},
+		{{String: "2020-05-17", Valid: true}, {String: "2020-06-01", Valid: true}},
+	}
+	for i, a := range anime {
+		if got := [2]sql.NullString{a.Startdate, a.Finishdate}; got != expected[i] {
+			t.Fatalf("anime %d: expected %#v, got %#v", a.ID, expected[i], got)
+		}
+	}
+}
+
+// Wildcards in the query only match themselves
+func TestSearchCachedAnimeWildcards(t *testing.T) {
+	cfg := newTestDB(t)
+	for _, anime := range []types.AnimeData{{MalID: 1, Title: "Cowboy Bebop"}, {MalID: 9, Title: "100% Pascal-sensei"}} {
+		if err := cfg.CacheAnime(anime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for query, expected := range map[string]int{"%": 1, "0% p": 1, "_": 0, `\`: 0, "bebop": 1} {
+		found, err := cfg.SearchCachedAnime(query, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != expected {
+			t.Errorf("%q: expected %d results, got %#v", query, expected, found)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	cfg := newTestDB(t)
+
+	if _, _, err := cfg.CachedAnime(1); err != sql.ErrNoRows {
+		t.Fatalf("expected sql.ErrNoRows for uncached anime, got %v", err)
+	}
+
+	anime := types.AnimeData{MalID: 1, Title: "Cowboy Bebop", TitleSynonyms: []string{"Bebop"}, Episodes: 26}
+	anime.Aired.From = "1998-04-03T00:00:00+00:00"
+	if err := cfg.CacheAnime(anime); err != nil {
+		t.Fatal(err)
+	}
+
+	cached, fetched, err := cfg.CachedAnime(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cached, anime) {
+		t.Fatalf("cached anime differs from input:\n%#v\n%#v\n", cached, anime)
+	}
+	if time.Since(fetched) > time.Minute {
+		t.Fatalf("expected fetchedAt to be now, got %v", fetched)
+	}
+
+	// Caching again should replace the old details
+	anime.Episodes = 27
+	if err := cfg.CacheAnime(anime); err != nil {
+		t.Fatal(err)
+	}
+	if cached, _, _ := cfg.CachedAnime(1); cached.Episodes != 27 {
+		t.Fatalf("expected 27 episodes after recaching, got %d", cached.Episodes)
+	}
+
+	found, err := cfg.SearchCachedAnime("bebop", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].MalID != 1 {
+		t.Fatalf("expected to find the cached anime by its synonym, got %#v", found)
+	}
+
+	// Listed but never cached anime count as stale
+	if err := cfg.UploadToDB(database.Anime{ID: 5, Title: "Trigun", Completion: types.Watching}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Conn.ExecContext(cfg.Ctx, "UPDATE metadata SET fetchedAt = '2000-01-01T00:00:00Z' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := cfg.StaleCacheIDs(DefaultCacheTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []int64{1, 5}) {
+		t.Fatalf("expected stale ids [1 5], got %v", stale)
+	}
+
+	if err := cfg.CacheAnime(types.AnimeData{MalID: 5, Title: "Trigun"}); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err := cfg.PruneCache(DefaultCacheTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 pruned anime, got %d", pruned)
+	}
+
+	pruned, err = cfg.PruneCache(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected the remaining anime to be pruned, got %d", pruned)
+	}
+}
+
+func TestFTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"  ":                      "",
+		"kaguya":                  `"kaguya"*`,
+		"kaguya sama":             `"kaguya"* "sama"*`,
+		`"love is" kaguya`:        `"love is" "kaguya"*`,
+		`"love is war`:            `"love is war"`,
+		"steins;gate AND NEAR(x)": `"steins;gate"* "AND"* "NEAR(x)"*`,
+	}
+
+	for input, expected := range tests {
+		if got := ftsQuery(input); got != expected {
+			t.Errorf("ftsQuery(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
+
+// Only runs when built with -tags sqlite_fts5
+func TestSearchIndex(t *testing.T) {
+	ddl, err := os.ReadFile("../sql/search/search_index.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestDB(t)
+	if err := cfg.UploadToDB(database.Anime{ID: 1, Title: "Kaguya-sama: Love is War", Completion: types.Completed}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.EnableSearchIndex(string(ddl)); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.SearchIndex {
+		// Writes still have to work without the index
+		if err := cfg.UploadToDB(database.Anime{ID: 2, Title: "Trigun", Completion: types.Watching}); err != nil {
+			t.Fatal(err)
+		}
+		t.Skip("SQLite was built without FTS5")
+	}
+
+	// Anime added before the index existed are picked up by the rebuild
+	found, err := cfg.SearchList("kagu", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != 1 {
+		t.Fatalf("expected to find the anime listed before the index, got %#v", found)
+	}
+
+	// Later changes are kept in sync by the triggers
+	if err := cfg.UploadToDB(database.Anime{ID: 2, Title: "Trigun", Completion: types.Watching}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.CacheAnime(types.AnimeData{MalID: 2, Title: "Trigun", Synopsis: "Vash the Stampede has a bounty on his head"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.CacheAnime(types.AnimeData{MalID: 3, Title: "Cowboy Bebop", TitleSynonyms: []string{"Bebop"}, Synopsis: "Bounty hunters in space"}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err = cfg.SearchList("bounty", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != 2 {
+		t.Fatalf("expected only listed anime to be found by synopsis, got %#v", found)
+	}
+
+	cached, err := cfg.SearchIndexedCache("bounty", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 2 {
+		t.Fatalf("expected both cached anime to be found, got %d", len(cached))
+	}
+
+	// A title match ranks above a synopsis match
+	if err := cfg.CacheAnime(types.AnimeData{MalID: 4, Title: "Space Dandy"}); err != nil {
+		t.Fatal(err)
+	}
+	cached, err = cfg.SearchIndexedCache("space", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cached) != 2 || cached[0].MalID != 4 {
+		t.Fatalf("expected the title match first, got %#v", cached)
+	}
+
+	if err := cfg.DB.DeleteAnime(cfg.Ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := cfg.SearchList("kaguya", 10); len(found) != 0 {
+		t.Fatalf("expected deleted anime to leave the index, got %#v", found)
+	}
+}
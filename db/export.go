@@ -0,0 +1,75 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/saubuny/haru/types"
+)
+
+// MAL spells some statuses differently to us
+func malStatus(completion string) string {
+	switch completion {
+	case types.PlanToWatch:
+		return "Plan to Watch"
+	case types.OnHold:
+		return "On-Hold"
+	}
+	return completion
+}
+
+// MAL always writes full dates, with 00 for whatever isn't known, so 2024-10 becomes 2024-10-00 and unset dates are 0000-00-00
+func malDate(date sql.NullString) string {
+	if !date.Valid {
+		return "0000-00-00"
+	}
+	return date.String + strings.Repeat("-00", 2-strings.Count(date.String, "-"))
+}
+
+// Writes the whole list as a MAL export, which MAL's importer (and ImportMAL) can read back. Every entry is marked to overwrite what's already on MAL
+func (cfg DBConfig) ExportMAL(w io.Writer) error {
+	anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+	if err != nil {
+		return err
+	}
+
+	list := types.Myanimelist{}
+	list.Myinfo.UserExportType = "1"
+
+	totals := map[string]int{}
+	for _, a := range anime {
+		totals[a.Completion]++
+		list.Anime = append(list.Anime, types.MyanimelistAnime{
+			SeriesAnimedbID:   strconv.Itoa(int(a.ID)),
+			SeriesTitle:       a.Title,
+			MyWatchedEpisodes: strconv.Itoa(int(a.Watchedepisodes)),
+			MyStartDate:       malDate(a.Startdate),
+			MyFinishDate:      malDate(a.Finishdate),
+			MyScore:           strconv.Itoa(int(a.Score)),
+			MyStatus:          malStatus(a.Completion),
+			MyTimesWatched:    strconv.Itoa(int(a.Timeswatched)),
+			UpdateOnImport:    "1",
+		})
+	}
+
+	list.Myinfo.UserTotalAnime = strconv.Itoa(len(anime))
+	list.Myinfo.UserTotalWatching = strconv.Itoa(totals[types.Watching])
+	list.Myinfo.UserTotalCompleted = strconv.Itoa(totals[types.Completed])
+	list.Myinfo.UserTotalOnhold = strconv.Itoa(totals[types.OnHold])
+	list.Myinfo.UserTotalDropped = strconv.Itoa(totals[types.Dropped])
+	list.Myinfo.UserTotalPlantowatch = strconv.Itoa(totals[types.PlanToWatch])
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(list); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
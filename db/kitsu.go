@@ -0,0 +1,270 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/types"
+)
+
+// Kitsu also exports in the MAL format, but its MAL IDs can be missing or out of date. Every entry gets checked against Jikan (which caches its details
+// too), and entries without an ID are matched by title. That's a request for every entry, so the import runs in the background, and can be stopped and
+// resumed since progress is saved after every entry
+
+// How many entries are looked up at once. Jikan only allows 3 requests a second, so more wouldn't make it any faster
+const KitsuWorkers = 3
+
+// What happened to one entry of a Kitsu import
+type KitsuResult struct {
+	Title  string
+	ID     int  // MAL ID it was added as, or 0 if no match was found
+	Queued bool // we're offline, so it'll be looked up later with its tracking kept
+	Err    error
+
+	anime   database.Anime
+	details *types.AnimeData
+}
+
+// Saved after every entry so an import can carry on where it stopped
+type kitsuState struct {
+	Source     string   `json:"source"` // hash of the export, so a different file starts over
+	Done       []int    `json:"done"`   // indexes of the entries already imported
+	Unresolved []string `json:"unresolved"`
+}
+
+type KitsuImport struct {
+	cfg       DBConfig
+	client    *jikan.Client
+	statePath string
+
+	entries []types.MyanimelistAnime
+	state   kitsuState
+	done    map[int]bool
+}
+
+// Reads a Kitsu export, picking up any progress saved in statePath from an earlier import of the same file
+func (cfg DBConfig) NewKitsuImport(kitsuXml []byte, client *jikan.Client, statePath string) (*KitsuImport, error) {
+	var animeList types.Myanimelist
+	if err := xml.Unmarshal(kitsuXml, &animeList); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(kitsuXml)
+	k := &KitsuImport{
+		cfg:       cfg,
+		client:    client,
+		statePath: statePath,
+		entries:   animeList.Anime,
+		state:     kitsuState{Source: hex.EncodeToString(hash[:]), Done: []int{}, Unresolved: []string{}},
+		done:      map[int]bool{},
+	}
+
+	saved, err := os.ReadFile(statePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var state kitsuState
+	if err == nil {
+		if err := json.Unmarshal(saved, &state); err != nil {
+			log.Printf("Ignoring unreadable Kitsu import progress: %v", err)
+		} else if state.Source == k.state.Source {
+			k.state = state
+		}
+	}
+	for _, i := range k.state.Done {
+		k.done[i] = true
+	}
+
+	return k, nil
+}
+
+// How many entries the export has
+func (k *KitsuImport) Total() int {
+	return len(k.entries)
+}
+
+// How many entries have been imported so far, including by earlier runs. Only up to date once Run's channel is closed
+func (k *KitsuImport) Done() int {
+	return len(k.done)
+}
+
+// Entries that couldn't be matched to a MAL ID, including from earlier runs. Only up to date once Run's channel is closed
+func (k *KitsuImport) Unresolved() []string {
+	return k.state.Unresolved
+}
+
+// Looks up and imports every entry that isn't done yet, sending each result as it's saved. The channel is closed once everything is imported, the
+// import fails, or ctx is cancelled (after finishing the lookups already in progress). Results are only sent once they're saved, so stopping never
+// loses anything
+func (k *KitsuImport) Run(ctx context.Context, workers int) <-chan KitsuResult {
+	ctx, cancel := context.WithCancel(ctx)
+
+	pending := []int{}
+	for i := range k.entries {
+		if !k.done[i] {
+			pending = append(pending, i)
+		}
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for _, i := range pending {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	type found struct {
+		index  int
+		result KitsuResult
+	}
+	lookedUp := make(chan found)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if result, ok := k.lookup(ctx, k.entries[i]); ok {
+					lookedUp <- found{index: i, result: result}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(lookedUp)
+	}()
+
+	// Everything is saved from here, so the state file and the database only have one writer
+	results := make(chan KitsuResult)
+	go func() {
+		defer close(results)
+		defer cancel()
+
+		failed := false
+		for f := range lookedUp {
+			// Still draining, so the workers don't get stuck
+			if failed {
+				continue
+			}
+			if err := k.save(f.index, f.result); err != nil {
+				f.result.Err = err
+				failed = true
+				cancel()
+			}
+			results <- f.result
+		}
+
+		if k.Done() == k.Total() {
+			if err := os.Remove(k.statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Could not remove Kitsu import progress: %v", err)
+			}
+		}
+	}()
+
+	return results
+}
+
+// Finds the MAL ID for an entry. ok is false when ctx was cancelled before it could be looked up
+func (k *KitsuImport) lookup(ctx context.Context, entry types.MyanimelistAnime) (KitsuResult, bool) {
+	anime := malAnime(entry)
+	result := KitsuResult{Title: anime.Title, anime: anime}
+
+	if anime.ID > 0 {
+		res, err := k.client.GetAnime(ctx, int(anime.ID))
+		switch {
+		case err == nil:
+			result.ID = res.Data.MalID
+			result.Title = res.Data.Title
+			result.details = &res.Data
+			return result, true
+		case ctx.Err() != nil:
+			return result, false
+		case errors.Is(err, jikan.ErrNotFound):
+			// Kitsu's mapping is wrong, so it's matched by title instead
+			log.Printf("MAL ID %d from Kitsu for %q doesn't exist", anime.ID, anime.Title)
+		default:
+			// Can't check it right now, but Kitsu's IDs are right nearly all the time
+			log.Printf("Could not check MAL ID %d for %q: %v", anime.ID, anime.Title, err)
+			result.ID = int(anime.ID)
+			return result, true
+		}
+	}
+
+	matches, err := k.client.SearchAnime(ctx, anime.Title, jikan.SearchFilters{Limit: resolveLimit}, 1)
+	if ctx.Err() != nil {
+		return result, false
+	}
+	if errors.Is(err, jikan.ErrOffline) {
+		result.Queued = true
+		return result, true
+	}
+	if err != nil {
+		log.Printf("Could not resolve %q: %v", anime.Title, err)
+		return result, true
+	}
+
+	if id, score := bestMatch(anime.Title, matches.Data); score >= DefaultResolveThreshold {
+		result.ID = id
+	}
+	return result, true
+}
+
+func (k *KitsuImport) save(index int, result KitsuResult) error {
+	switch {
+	case result.Queued:
+		if err := k.cfg.queueLookup(result.Title, result.Title, "", result.anime); err != nil {
+			return err
+		}
+	case result.ID == 0:
+		k.state.Unresolved = append(k.state.Unresolved, result.Title)
+	default:
+		anime := result.anime
+		anime.ID = int64(result.ID)
+		anime.Title = result.Title
+		if err := k.cfg.UploadToDB(anime); err != nil {
+			return err
+		}
+		if result.details != nil {
+			if err := k.cfg.CacheAnime(*result.details); err != nil {
+				return err
+			}
+		}
+	}
+
+	k.done[index] = true
+	k.state.Done = append(k.state.Done, index)
+	return k.saveState()
+}
+
+// Written to a temporary file first, so stopping halfway through a write can't corrupt it
+func (k *KitsuImport) saveState() error {
+	data, err := json.Marshal(k.state)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.statePath), 0o755); err != nil {
+		return err
+	}
+	tmp := k.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, k.statePath)
+}
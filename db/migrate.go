@@ -0,0 +1,146 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A single numbered schema change, loaded from a file like 0002_tracking_columns.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Loads every .sql file in the root of fsys. Versions have to start at 1 and have no gaps, so a missing file can't be silently skipped
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []Migration{}
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %q must be named like 0001_description.sql", file)
+		}
+
+		contents, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(contents)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("expected migration %04d but found %04d_%s", i+1, migration.Version, migration.Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// The version stored in the database file through PRAGMA user_version
+func (cfg DBConfig) userVersion() (int, error) {
+	var version int
+	err := cfg.Conn.QueryRowContext(cfg.Ctx, "PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+// Applies every migration newer than the database, each in its own transaction. Refuses to touch a database made by a newer version of haru
+func (cfg DBConfig) Migrate(migrations []Migration) error {
+	version, err := cfg.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database is at schema version %d, but this build of haru only knows up to %d. Please update haru", version, len(migrations))
+	}
+
+	for _, migration := range migrations[version:] {
+		if err := cfg.applyMigration(migration); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Lists every migration and whether the database already has it
+func (cfg DBConfig) MigrationStatus(migrations []Migration) ([]MigrationStatus, error) {
+	version, err := cfg.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range migrations {
+		statuses = append(statuses, MigrationStatus{Migration: migration, Applied: migration.Version <= version})
+	}
+	return statuses, nil
+}
+
+func (cfg DBConfig) applyMigration(migration Migration) error {
+	tx, err := cfg.Conn.BeginTx(cfg.Ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(cfg.Ctx, migration.SQL); err != nil {
+		return err
+	}
+
+	// user_version is part of the database header, so it gets rolled back along with everything else
+	if _, err := tx.ExecContext(cfg.Ctx, fmt.Sprintf("PRAGMA user_version = %d", migration.Version)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// The last migration applied to the database. Databases made before migrations were versioned get worked out from the anime table
+func (cfg DBConfig) SchemaVersion() (int, error) {
+	version, err := cfg.userVersion()
+	if err != nil || version != 0 {
+		return version, err
+	}
+
+	var table string
+	err = cfg.Conn.QueryRowContext(cfg.Ctx, "SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'anime'").Scan(&table)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Older builds added the tracking columns on startup instead of through 0002
+	var hasTracking bool
+	err = cfg.Conn.QueryRowContext(cfg.Ctx, "SELECT COUNT(*) > 0 FROM pragma_table_info('anime') WHERE name = 'watchedEpisodes'").Scan(&hasTracking)
+	if err != nil {
+		return 0, err
+	}
+	if hasTracking {
+		return 2, nil
+	}
+	return 1, nil
+}
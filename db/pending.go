@@ -0,0 +1,81 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/jikan"
+)
+
+// Saves an imported entry to be matched to a MAL ID once we're back online, along with its tracking (everything in tracking apart from the ID and title)
+func (cfg DBConfig) queueLookup(title, name, link string, tracking database.Anime) error {
+	return cfg.DB.CreatePendingLookup(cfg.Ctx, database.CreatePendingLookupParams{
+		Title:           title,
+		Name:            name,
+		Link:            link,
+		Completion:      tracking.Completion,
+		Queuedat:        time.Now().UTC().Format(time.RFC3339),
+		Watchedepisodes: tracking.Watchedepisodes,
+		Score:           tracking.Score,
+		Timeswatched:    tracking.Timeswatched,
+		Startdate:       tracking.Startdate,
+		Finishdate:      tracking.Finishdate,
+	})
+}
+
+// How many imported entries are still waiting to be looked up
+func (cfg DBConfig) PendingLookupCount() (int64, error) {
+	return cfg.DB.CountPendingLookups(cfg.Ctx)
+}
+
+// Looks up queued entries again, adding the ones that match to the list unless they were added some other way in the meantime. Entries that still can't be matched are dropped from the queue and returned so they can be added manually.
+// Stops early if we go offline again, leaving the rest queued
+func (cfg DBConfig) RetryPendingLookups(resolve Resolver) (added int, unresolved []string, err error) {
+	pending, err := cfg.DB.GetPendingLookups(cfg.Ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, lookup := range pending {
+		id, ok, err := resolve(lookup.Title)
+		if errors.Is(err, jikan.ErrOffline) {
+			return added, unresolved, nil
+		}
+		if err != nil {
+			// Could just be Jikan having a bad moment, so keep it queued
+			log.Printf("Could not resolve %q: %v", lookup.Name, err)
+			continue
+		}
+
+		if !ok {
+			unresolved = append(unresolved, fmt.Sprintf("%s (%s)", lookup.Name, lookup.Link))
+		} else if _, err := cfg.DB.GetAnime(cfg.Ctx, int64(id)); err == sql.ErrNoRows {
+			err := cfg.UploadToDB(database.Anime{
+				ID:              int64(id),
+				Title:           lookup.Name,
+				Completion:      lookup.Completion,
+				Watchedepisodes: lookup.Watchedepisodes,
+				Score:           lookup.Score,
+				Timeswatched:    lookup.Timeswatched,
+				Startdate:       lookup.Startdate,
+				Finishdate:      lookup.Finishdate,
+			})
+			if err != nil {
+				return added, unresolved, err
+			}
+			added++
+		} else if err != nil {
+			return added, unresolved, err
+		}
+
+		if err := cfg.DB.DeletePendingLookup(cfg.Ctx, lookup.ID); err != nil {
+			return added, unresolved, err
+		}
+	}
+
+	return added, unresolved, nil
+}
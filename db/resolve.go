@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/types"
+)
+
+// Resolves a title to a MAL ID. ok is false when nothing matched closely enough to be trusted
+type Resolver func(title string) (id int, ok bool, err error)
+
+// Minimum similarity (0-1) between the searched title and a result before we accept it
+const DefaultResolveThreshold = 0.8
+
+// Search results compared against a title. The right one is nearly always near the top, and fewer results make the response much smaller
+const resolveLimit = 5
+
+// Searches Jikan for the title and picks the closest result, as long as it clears the threshold
+func JikanResolver(client *jikan.Client, threshold float64) Resolver {
+	return func(title string) (int, bool, error) {
+		results, err := client.SearchAnime(context.Background(), title, jikan.SearchFilters{Limit: resolveLimit}, 1)
+		if err != nil {
+			return 0, false, err
+		}
+
+		id, score := bestMatch(title, results.Data)
+		return id, score >= threshold, nil
+	}
+}
+
+// Returns the ID of the result with the most similar title, along with that similarity
+func bestMatch(title string, results []types.AnimeData) (int, float64) {
+	bestID, bestScore := 0, 0.0
+	for _, anime := range results {
+		candidates := append([]string{anime.Title, anime.TitleEnglish, anime.TitleJapanese}, anime.TitleSynonyms...)
+		for _, t := range anime.Titles {
+			candidates = append(candidates, t.Title)
+		}
+
+		for _, candidate := range candidates {
+			if candidate == "" {
+				continue
+			}
+			if score := similarity(title, candidate); score > bestScore {
+				bestID, bestScore = anime.MalID, score
+			}
+		}
+	}
+	return bestID, bestScore
+}
+
+// Normalised Levenshtein similarity between two titles, ignoring case and punctuation
+func similarity(a, b string) float64 {
+	ra, rb := []rune(normaliseTitle(a)), []rune(normaliseTitle(b))
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// Lowercases a title and collapses everything that isn't a letter or number into single spaces
+func normaliseTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// Turns a Hianime link like https://hianime.to/watch/one-piece-100 into "one piece"
+func hianimeSlugTitle(link string) string {
+	slug := link
+	if u, err := url.Parse(link); err == nil && u.Path != "" {
+		slug = u.Path
+	}
+	slug = strings.Trim(slug, "/")
+	if i := strings.LastIndex(slug, "/"); i >= 0 {
+		slug = slug[i+1:]
+	}
+
+	// Hianime appends its own numeric ID to every slug
+	words := strings.Split(slug, "-")
+	if len(words) > 1 && strings.IndexFunc(words[len(words)-1], func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/saubuny/haru/internal/database"
+	"github.com/saubuny/haru/types"
+)
+
+// Sets up the FTS5 search index from ddl (sql/search/search_index.sql) if this build of SQLite has FTS5, and sets cfg.SearchIndex if it worked.
+// Without FTS5 the index's triggers are dropped instead, since they would break every write. The index is rebuilt whenever its triggers are missing
+func (cfg *DBConfig) EnableSearchIndex(ddl string) error {
+	var available bool
+	if err := cfg.Conn.QueryRowContext(cfg.Ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return err
+	}
+
+	tx, err := cfg.Conn.BeginTx(cfg.Ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(cfg.Ctx, "SELECT name FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'anime_search_%'")
+	if err != nil {
+		return err
+	}
+	triggers := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		triggers = append(triggers, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !available {
+		for _, trigger := range triggers {
+			if _, err := tx.ExecContext(cfg.Ctx, "DROP TRIGGER "+trigger); err != nil {
+				return err
+			}
+		}
+		cfg.SearchIndex = false
+		return tx.Commit()
+	}
+
+	if _, err := tx.ExecContext(cfg.Ctx, ddl); err != nil {
+		return err
+	}
+
+	// Either the index is new, or writes happened without it keeping up
+	if len(triggers) == 0 {
+		q := cfg.DB.WithTx(tx)
+		if err := q.ClearSearchIndex(cfg.Ctx); err != nil {
+			return err
+		}
+		if err := q.RebuildSearchIndex(cfg.Ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	cfg.SearchIndex = true
+	return nil
+}
+
+// Anime in the list matching query, best first. Titles count more than synopses. Only works if cfg.SearchIndex is set
+func (cfg DBConfig) SearchList(query string, limit int) ([]database.Anime, error) {
+	if ftsQuery(query) == "" {
+		return nil, nil
+	}
+
+	return cfg.DB.SearchListedAnime(cfg.Ctx, database.SearchListedAnimeParams{
+		Query:      ftsQuery(query),
+		MaxResults: int64(limit),
+	})
+}
+
+// Cached anime matching query, best first. Only works if cfg.SearchIndex is set
+func (cfg DBConfig) SearchIndexedCache(query string, limit int) ([]types.AnimeData, error) {
+	if ftsQuery(query) == "" {
+		return nil, nil
+	}
+
+	rows, err := cfg.DB.SearchIndexedMetadata(cfg.Ctx, database.SearchIndexedMetadataParams{
+		Query:      ftsQuery(query),
+		MaxResults: int64(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeMetadataRows(rows)
+}
+
+// Turns what was typed into an FTS5 query. Words in double quotes have to appear together as a phrase, and any other word matches as a prefix, so
+// `"love is" kaguya` becomes `"love is" "kaguya"*`. Everything is quoted so FTS5 syntax like AND or NEAR is searched for literally
+func ftsQuery(input string) string {
+	terms := []string{}
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+
+	for i, part := range strings.Split(input, `"`) {
+		// Every other part is inside quotes, including an unfinished quote at the end
+		if i%2 == 1 {
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, quote(phrase))
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			terms = append(terms, quote(word)+"*")
+		}
+	}
+
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,146 @@
+package editprompt
+
+// The prompt for changing a tracking field, shared by the list and the info page. Saving is left to the screens, since they update different things
+// afterwards
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/dates"
+	"github.com/saubuny/haru/internal/database"
+)
+
+var errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+// Which tracking field the prompt is currently changing
+type Field int
+
+const (
+	None Field = iota
+	Episodes
+	Score
+	StartDate
+	FinishDate
+	TimesWatched
+	// The start date asked for when adding an anime
+	AddStartDate
+)
+
+// Shown in empty date prompts as a reminder of what can be typed
+const DatePlaceholder = "today, yesterday, -3d, 2024-05 or unknown"
+
+func (f Field) String() string {
+	switch f {
+	case Episodes:
+		return "Episodes watched"
+	case Score:
+		return "Score (0-10)"
+	case StartDate, AddStartDate:
+		return "Start date"
+	case FinishDate:
+		return "Finish date"
+	case TimesWatched:
+		return "Times rewatched"
+	}
+	return ""
+}
+
+func (f Field) isDate() bool {
+	return f == StartDate || f == FinishDate || f == AddStartDate
+}
+
+// The field's current value for an anime in the list, to prefill the prompt with
+func Value(f Field, anime database.Anime) string {
+	switch f {
+	case Episodes:
+		return strconv.Itoa(int(anime.Watchedepisodes))
+	case Score:
+		return strconv.Itoa(int(anime.Score))
+	case StartDate:
+		return dates.Edit(anime.Startdate)
+	case FinishDate:
+		return dates.Edit(anime.Finishdate)
+	case TimesWatched:
+		return strconv.Itoa(int(anime.Timeswatched))
+	}
+	return ""
+}
+
+type Model struct {
+	Field Field
+	Err   string
+	Input textinput.Model
+}
+
+func New() Model {
+	ei := textinput.New()
+	ei.CharLimit = 20
+	return Model{Input: ei}
+}
+
+func (m Model) Editing() bool {
+	return m.Field != None
+}
+
+// Opens the prompt for a field, prefilled with value
+func (m *Model) Start(field Field, value string) {
+	m.Field = field
+	m.Err = ""
+	m.Input.Prompt = field.String() + ": "
+	m.Input.Placeholder = ""
+	if field.isDate() {
+		m.Input.Placeholder = DatePlaceholder
+	}
+	m.Input.SetValue(value)
+	m.Input.CursorEnd()
+	m.Input.Focus()
+}
+
+func (m *Model) Stop() {
+	m.Field = None
+	m.Err = ""
+	m.Input.Reset()
+	m.Input.Blur()
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.Input, cmd = m.Input.Update(msg)
+	return m, cmd
+}
+
+// Checks the prompt holds a number the field allows
+func (m Model) Number() (int64, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(m.Input.Value()))
+	if m.Field == Score {
+		if err != nil || n < 0 || n > 10 {
+			return 0, fmt.Errorf("must be a whole number from 0 to 10")
+		}
+		return int64(n), nil
+	}
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("must be a whole number, 0 or more")
+	}
+	return int64(n), nil
+}
+
+// Reads the prompt as a date, counting from today
+func (m Model) Date() (sql.NullString, error) {
+	return dates.Parse(strings.TrimSpace(m.Input.Value()), time.Now())
+}
+
+// The prompt, with the last error after it
+func (m Model) View() string {
+	prompt := m.Input.View()
+	if m.Err != "" {
+		prompt += "  " + errorStyle.Render(m.Err)
+	}
+	return prompt
+}
@@ -0,0 +1,48 @@
+package editprompt
+
+import "testing"
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		field Field
+		value string
+		want  int64
+		ok    bool
+	}{
+		{Episodes, " 12 ", 12, true},
+		{Episodes, "0", 0, true},
+		{Episodes, "-1", 0, false},
+		{Episodes, "twelve", 0, false},
+		{TimesWatched, "40", 40, true},
+		{Score, "10", 10, true},
+		{Score, "11", 0, false},
+	}
+
+	m := New()
+	for _, tt := range tests {
+		m.Start(tt.field, tt.value)
+		got, err := m.Number()
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("%s %q: got %d, %v", tt.field, tt.value, got, err)
+		}
+	}
+}
+
+func TestStart(t *testing.T) {
+	m := New()
+	m.Start(FinishDate, "")
+	if m.Input.Placeholder != DatePlaceholder || m.Input.Prompt != "Finish date: " {
+		t.Fatalf("expected a date prompt, got %q with placeholder %q", m.Input.Prompt, m.Input.Placeholder)
+	}
+
+	m.Start(Score, "7")
+	if m.Input.Placeholder != "" || m.Input.Value() != "7" {
+		t.Fatalf("expected the score prefilled without a placeholder, got %q and %q", m.Input.Value(), m.Input.Placeholder)
+	}
+
+	m.Err = "must be a whole number"
+	m.Stop()
+	if m.Editing() || m.Err != "" || m.Input.Value() != "" {
+		t.Fatalf("expected stopping to clear the prompt, got %+v", m)
+	}
+}
@@ -0,0 +1,118 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/saubuny/haru/config"
+	"github.com/saubuny/haru/jikan"
+)
+
+// The browse tab's search filters. The form is pushed onto the navstack, and sends an AppliedMessage to the screen below once it's popped
+
+// Sent after the form closes with new filters, which have already been saved
+type AppliedMessage jikan.SearchFilters
+
+// Where the last used filters are kept, in the state dir next to the log
+func path() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "filters.json"), nil
+}
+
+// Reads the last used filters. No saved filters is the same as no filters
+func Load() (jikan.SearchFilters, error) {
+	var filters jikan.SearchFilters
+
+	p, err := path()
+	if err != nil {
+		return filters, err
+	}
+
+	file, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return filters, nil
+	}
+	if err != nil {
+		return filters, err
+	}
+
+	if err := json.Unmarshal(file, &filters); err != nil {
+		return jikan.SearchFilters{}, err
+	}
+	return filters, nil
+}
+
+// Remembers the filters for next time
+func Save(filters jikan.SearchFilters) error {
+	p, err := path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	file, err := json.MarshalIndent(filters, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, file, 0o644)
+}
+
+// Short descriptions of every filter that's set, for showing above the results
+func Describe(filters jikan.SearchFilters) []string {
+	parts := []string{}
+	add := func(options []jikan.Option, value string) {
+		if value != "" {
+			parts = append(parts, jikan.Label(options, value))
+		}
+	}
+
+	add(jikan.Types, filters.Type)
+	add(jikan.Statuses, filters.Status)
+	if filters.Rating != "" {
+		// The full labels are long, so only the part before the dash
+		parts = append(parts, strings.TrimSpace(strings.SplitN(jikan.Label(jikan.Ratings, filters.Rating), " - ", 2)[0]))
+	}
+
+	genres := []string{}
+	for _, id := range filters.Genres {
+		genres = append(genres, jikan.Label(jikan.Genres, strconv.Itoa(id)))
+	}
+	if len(genres) > 0 {
+		parts = append(parts, strings.Join(genres, ", "))
+	}
+
+	if filters.MinScore > 0 {
+		parts = append(parts, "score ≥ "+strconv.FormatFloat(filters.MinScore, 'f', -1, 64))
+	}
+	switch {
+	case filters.StartDate != "" && filters.EndDate != "":
+		parts = append(parts, fmt.Sprintf("%s to %s", filters.StartDate, filters.EndDate))
+	case filters.StartDate != "":
+		parts = append(parts, "from "+filters.StartDate)
+	case filters.EndDate != "":
+		parts = append(parts, "until "+filters.EndDate)
+	}
+
+	if filters.OrderBy != "" {
+		order := "by " + strings.ToLower(jikan.Label(jikan.Orders, filters.OrderBy))
+		if filters.Sort != "" {
+			order += ", " + strings.ToLower(jikan.Label(jikan.Sorts, filters.Sort))
+		}
+		parts = append(parts, order)
+	} else if filters.Sort != "" {
+		parts = append(parts, strings.ToLower(jikan.Label(jikan.Sorts, filters.Sort)))
+	}
+
+	return parts
+}
@@ -0,0 +1,92 @@
+package filters
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/saubuny/haru/jikan"
+)
+
+func TestSaveLoad(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Active() {
+		t.Fatalf("expected no filters before any were saved, got %#v", loaded)
+	}
+
+	saved := jikan.SearchFilters{Type: "tv", Genres: []int{1, 22}, MinScore: 7.5, StartDate: "2020"}
+	if err := Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, saved) {
+		t.Fatalf("loaded filters differ from saved:\n%#v\n%#v", loaded, saved)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	filters := jikan.SearchFilters{
+		Type:      "tv",
+		Status:    "airing",
+		Rating:    "pg13",
+		Genres:    []int{1, 22},
+		MinScore:  7,
+		StartDate: "2020",
+		OrderBy:   "score",
+		Sort:      "desc",
+	}
+	expected := []string{"TV", "Airing", "PG-13", "Action, Romance", "score ≥ 7", "from 2020", "by score, descending"}
+
+	if got := Describe(filters); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	if got := Describe(jikan.SearchFilters{}); len(got) != 0 {
+		t.Fatalf("expected nothing for no filters, got %q", got)
+	}
+}
+
+func TestForm(t *testing.T) {
+	filters := jikan.SearchFilters{Status: "complete", Genres: []int{4}, MinScore: 8, EndDate: "2010-06", OrderBy: "members"}
+
+	// Opening the form and applying straight away keeps the same filters
+	m := New(filters)
+	got, err := m.filters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, filters) {
+		t.Fatalf("form changed the filters:\n%#v\n%#v", got, filters)
+	}
+
+	// Type goes from Any to TV, then the first genre is toggled on
+	var model tea.Model = m
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyRight}, {Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeyDown}, {Type: tea.KeySpace, Runes: []rune{' '}}} {
+		model, _ = model.Update(msg)
+	}
+	got, err = model.(Model).filters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != "tv" || !reflect.DeepEqual(got.Genres, []int{1, 4}) {
+		t.Fatalf("expected type tv and genres [1 4], got %#v", got)
+	}
+
+	m = model.(Model)
+	m.fields[startDateField].input.SetValue("2010-13")
+	if _, err := m.filters(); err == nil {
+		t.Fatal("expected an invalid month to be rejected")
+	}
+	m.fields[startDateField].input.SetValue("")
+	m.fields[minScoreField].input.SetValue("11")
+	if _, err := m.filters(); err == nil {
+		t.Fatal("expected a score over 10 to be rejected")
+	}
+}
@@ -0,0 +1,378 @@
+package filters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/navstack"
+)
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	labelStyle    = lipgloss.NewStyle().Width(14)
+	focusedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	genreStyle    = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("240"))
+	selectedGenre = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+)
+
+type fieldKind int
+
+const (
+	choiceField fieldKind = iota
+	genreField
+	textField
+)
+
+// One row of the form. Choices cycle through options with "Any" first, genres are toggled one by one, and text is typed and checked by validate
+type field struct {
+	label string
+	kind  fieldKind
+
+	options []jikan.Option
+	choice  int // 0 is Any, otherwise options[choice-1]
+
+	input    textinput.Model
+	validate func(string) error
+}
+
+func choice(label string, options []jikan.Option, value string) field {
+	f := field{label: label, kind: choiceField, options: options}
+	for i, o := range options {
+		if o.Value == value {
+			f.choice = i + 1
+		}
+	}
+	return f
+}
+
+func text(label, value, placeholder string, validate func(string) error) field {
+	ti := textinput.New()
+	ti.CharLimit = 10
+	ti.Width = 12
+	ti.Prompt = ""
+	ti.Placeholder = placeholder
+	ti.SetValue(value)
+	return field{label: label, kind: textField, input: ti, validate: validate}
+}
+
+func (f field) value() string {
+	switch f.kind {
+	case choiceField:
+		if f.choice == 0 {
+			return ""
+		}
+		return f.options[f.choice-1].Value
+	case textField:
+		return strings.TrimSpace(f.input.Value())
+	}
+	return ""
+}
+
+// The order fields are shown in
+const (
+	typeField = iota
+	statusField
+	ratingField
+	genresField
+	minScoreField
+	startDateField
+	endDateField
+	orderByField
+	sortField
+)
+
+type Model struct {
+	width  int
+	height int
+
+	fields  []field
+	focused int
+	err     string
+
+	// Genre the cursor is on, and the IDs of every selected genre
+	genreCursor int
+	genres      map[int]bool
+
+	help help.Model
+}
+
+// Form for changing the browse tab's filters, starting from the ones in use
+func New(current jikan.SearchFilters) Model {
+	help := help.New()
+	help.ShowAll = true
+
+	m := Model{help: help}
+	m.reset(current)
+	return m
+}
+
+func (m *Model) reset(filters jikan.SearchFilters) {
+	minScore := ""
+	if filters.MinScore > 0 {
+		minScore = strconv.FormatFloat(filters.MinScore, 'f', -1, 64)
+	}
+
+	m.fields = []field{
+		typeField:      choice("Type", jikan.Types, filters.Type),
+		statusField:    choice("Status", jikan.Statuses, filters.Status),
+		ratingField:    choice("Rating", jikan.Ratings, filters.Rating),
+		genresField:    {label: "Genres", kind: genreField},
+		minScoreField:  text("Min score", minScore, "0-10", validateScore),
+		startDateField: text("Aired from", filters.StartDate, "YYYY-MM-DD", validateDate),
+		endDateField:   text("Aired until", filters.EndDate, "YYYY-MM-DD", validateDate),
+		orderByField:   choice("Order by", jikan.Orders, filters.OrderBy),
+		sortField:      choice("Sort", jikan.Sorts, filters.Sort),
+	}
+
+	m.genres = map[int]bool{}
+	for _, id := range filters.Genres {
+		m.genres[id] = true
+	}
+	m.err = ""
+	m.focus(m.focused)
+}
+
+func validateScore(value string) error {
+	if value == "" {
+		return nil
+	}
+	score, err := strconv.ParseFloat(value, 64)
+	if err != nil || score < 0 || score > 10 {
+		return fmt.Errorf("min score must be a number from 0 to 10")
+	}
+	return nil
+}
+
+// Jikan takes a year, a month or a full date
+func validateDate(value string) error {
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006", "2006-01", "2006-01-02"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("dates look like 2024, 2024-05 or 2024-05-31")
+}
+
+// The filters currently filled in, or the first field that isn't valid
+func (m Model) filters() (jikan.SearchFilters, error) {
+	for _, f := range m.fields {
+		if f.kind != textField {
+			continue
+		}
+		if err := f.validate(f.value()); err != nil {
+			return jikan.SearchFilters{}, err
+		}
+	}
+
+	filters := jikan.SearchFilters{
+		Type:      m.fields[typeField].value(),
+		Status:    m.fields[statusField].value(),
+		Rating:    m.fields[ratingField].value(),
+		StartDate: m.fields[startDateField].value(),
+		EndDate:   m.fields[endDateField].value(),
+		OrderBy:   m.fields[orderByField].value(),
+		Sort:      m.fields[sortField].value(),
+	}
+	if score := m.fields[minScoreField].value(); score != "" {
+		filters.MinScore, _ = strconv.ParseFloat(score, 64)
+	}
+
+	// In the same order as the form, so they're described the same way every time
+	for _, g := range jikan.Genres {
+		if id, _ := strconv.Atoi(g.Value); m.genres[id] {
+			filters.Genres = append(filters.Genres, id)
+		}
+	}
+	return filters, nil
+}
+
+// Moves to another field, wrapping around, and focuses its text input if it has one
+func (m *Model) focus(i int) {
+	m.focused = (i + len(m.fields)) % len(m.fields)
+	for i := range m.fields {
+		if m.fields[i].kind != textField {
+			continue
+		}
+		if i == m.focused {
+			m.fields[i].input.Focus()
+		} else {
+			m.fields[i].input.Blur()
+		}
+	}
+}
+
+// Left and right cycle through the options, or move between genres
+func (m *Model) move(by int) {
+	f := &m.fields[m.focused]
+	switch f.kind {
+	case choiceField:
+		n := len(f.options) + 1
+		f.choice = (f.choice + by + n) % n
+	case genreField:
+		m.genreCursor = (m.genreCursor + by + len(jikan.Genres)) % len(jikan.Genres)
+	}
+}
+
+func (m Model) apply() (Model, tea.Cmd) {
+	filters, err := m.filters()
+	if err != nil {
+		m.err = err.Error()
+		return m, nil
+	}
+
+	cmds := []tea.Cmd{navstack.Cmd(navstack.PopNavigation{}), navstack.Cmd(AppliedMessage(filters))}
+	if err := Save(filters); err != nil {
+		// Still worth searching with them, they just won't be remembered
+		cmds = append(cmds, navstack.Cmd(navstack.Notify("Couldn't save filters: "+err.Error())))
+	}
+	return m, tea.Sequence(cmds...)
+}
+
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+	case tea.KeyMsg:
+		f := m.fields[m.focused]
+		switch {
+		case key.Matches(msg, FiltersKeyMap.Esc):
+			return m, navstack.Cmd(navstack.PopNavigation{})
+		case key.Matches(msg, FiltersKeyMap.Apply):
+			return m.apply()
+		case key.Matches(msg, FiltersKeyMap.Clear):
+			m.reset(jikan.SearchFilters{})
+			return m, nil
+		case key.Matches(msg, FiltersKeyMap.Up):
+			m.focus(m.focused - 1)
+			return m, nil
+		case key.Matches(msg, FiltersKeyMap.Down):
+			m.focus(m.focused + 1)
+			return m, nil
+		case f.kind != textField && key.Matches(msg, FiltersKeyMap.Left):
+			m.move(-1)
+			return m, nil
+		case f.kind != textField && key.Matches(msg, FiltersKeyMap.Right):
+			m.move(1)
+			return m, nil
+		case f.kind == genreField && key.Matches(msg, FiltersKeyMap.Toggle):
+			id, _ := strconv.Atoi(jikan.Genres[m.genreCursor].Value)
+			m.genres[id] = !m.genres[id]
+			return m, nil
+		}
+
+		if f.kind == textField {
+			var cmd tea.Cmd
+			m.fields[m.focused].input, cmd = m.fields[m.focused].input.Update(msg)
+			m.err = ""
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if m.fields[m.focused].kind == textField {
+		var cmd tea.Cmd
+		m.fields[m.focused].input, cmd = m.fields[m.focused].input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) fieldView(i int, width int) string {
+	f := m.fields[i]
+	focused := i == m.focused
+
+	value := ""
+	switch f.kind {
+	case choiceField:
+		value = "Any"
+		if f.choice > 0 {
+			value = f.options[f.choice-1].Label
+		}
+		if focused {
+			value = "‹ " + value + " ›"
+		} else if f.choice == 0 {
+			value = dimStyle.Render(value)
+		}
+	case genreField:
+		value = m.genresView(focused, width)
+	case textField:
+		value = f.input.View()
+	}
+
+	cursor := "  "
+	label := labelStyle.Render(f.label)
+	if focused {
+		cursor = focusedStyle.Render("› ")
+		label = focusedStyle.Inherit(labelStyle).Render(f.label)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cursor, label, value)
+}
+
+// Every genre as a chip, wrapped to width. Selected ones are filled in, and the cursor is underlined while the row is focused
+func (m Model) genresView(focused bool, width int) string {
+	lines := []string{}
+	line := ""
+	for i, g := range jikan.Genres {
+		id, _ := strconv.Atoi(g.Value)
+		style := genreStyle
+		if m.genres[id] {
+			style = selectedGenre
+		}
+		if focused && i == m.genreCursor {
+			style = style.Underline(true)
+		}
+
+		chip := style.Render(g.Label)
+		if line != "" && lipgloss.Width(line)+lipgloss.Width(chip) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		line += chip
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	pageWidth := int(float64(m.width) * 0.8)
+	// Room for the cursor and labels
+	valueWidth := max(20, pageWidth-2-labelStyle.GetWidth())
+
+	rows := []string{titleStyle.Render("Search filters"), ""}
+	for i := range m.fields {
+		rows = append(rows, m.fieldView(i, valueWidth))
+	}
+	rows = append(rows, "")
+	if m.err != "" {
+		rows = append(rows, errorStyle.Render(m.err), "")
+	}
+	rows = append(rows, m.help.View(FiltersKeyMap))
+
+	render := lipgloss.NewStyle().Width(pageWidth).Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, render)
+}
@@ -0,0 +1,33 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How long the rest of the import should take, going by how fast it's been so far. ok is false until there's enough to go on
+func eta(done, remaining int, elapsed time.Duration) (left time.Duration, ok bool) {
+	if done == 0 || elapsed <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(elapsed) / float64(done) * float64(remaining)), true
+}
+
+// e.g. about 3m20s left. Rounded more the longer it is, since it's only a guess
+func etaView(left time.Duration) string {
+	switch {
+	case left < time.Minute:
+		return "less than a minute left"
+	case left < 10*time.Minute:
+		left = left.Round(10 * time.Second)
+	default:
+		left = left.Round(time.Minute)
+	}
+	// Durations always end in seconds, even when there aren't any
+	view := left.String()
+	if left%time.Minute == 0 {
+		view = strings.TrimSuffix(view, "0s")
+	}
+	return fmt.Sprintf("about %s left", view)
+}
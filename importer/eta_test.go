@@ -0,0 +1,35 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestETA(t *testing.T) {
+	if _, ok := eta(0, 10, time.Minute); ok {
+		t.Fatal("expected no estimate before anything is done")
+	}
+
+	left, ok := eta(20, 100, 10*time.Second)
+	if !ok || left != 50*time.Second {
+		t.Fatalf("expected 50s, got %v", left)
+	}
+}
+
+func TestETAView(t *testing.T) {
+	tests := []struct {
+		left     time.Duration
+		expected string
+	}{
+		{50 * time.Second, "less than a minute left"},
+		{3*time.Minute + 24*time.Second, "about 3m20s left"},
+		{3*time.Minute + 58*time.Second, "about 4m left"},
+		{42*time.Minute + 40*time.Second, "about 43m left"},
+	}
+
+	for _, test := range tests {
+		if got := etaView(test.left); got != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.left, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/db"
+)
+
+// Shows how far along a Kitsu import is, for haru import --platform kitsu. It runs on its own rather than on the navstack, and quits once the
+// import is done or stopped
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	dimStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+const maxBarWidth = 60
+
+// Sent every second so the estimate keeps counting down between results
+type tickMessage time.Time
+
+type resultMessage db.KitsuResult
+
+// The import's channel was closed
+type finishedMessage struct{}
+
+type Model struct {
+	width int
+
+	results <-chan db.KitsuResult
+	cancel  context.CancelFunc
+
+	total int
+	done  int
+	// Done by an earlier run, which shouldn't count towards how fast this one is going
+	resumed int
+	started time.Time
+	// When the last result came in, which the estimate counts down from
+	lastAt time.Time
+	now    time.Time
+
+	imported   int
+	unresolved int
+	queued     int
+	last       string
+
+	stopping  bool
+	cancelled bool
+	finished  bool
+	err       error
+
+	progress progress.Model
+	help     help.Model
+}
+
+// Starts importing straight away, so the lookups are already going by the time the program draws anything
+func New(kitsu *db.KitsuImport) Model {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Read before Run starts saving, since Done changes as entries are imported
+	total, done := kitsu.Total(), kitsu.Done()
+
+	now := time.Now()
+	return Model{
+		results:  kitsu.Run(ctx, db.KitsuWorkers),
+		cancel:   cancel,
+		total:    total,
+		done:     done,
+		resumed:  done,
+		started:  now,
+		lastAt:   now,
+		now:      now,
+		progress: progress.New(progress.WithDefaultGradient()),
+		help:     help.New(),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.waitForResult(), tick(), m.progress.SetPercent(m.percent()))
+}
+
+func tick() tea.Cmd {
+	return tea.Every(time.Second, func(t time.Time) tea.Msg {
+		return tickMessage(t)
+	})
+}
+
+func (m Model) waitForResult() tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-m.results
+		if !ok {
+			return finishedMessage{}
+		}
+		return resultMessage(result)
+	}
+}
+
+func (m Model) percent() float64 {
+	if m.total == 0 {
+		return 1
+	}
+	return float64(m.done) / float64(m.total)
+}
+
+// Whether the import was stopped before everything was imported
+func (m Model) Cancelled() bool {
+	return m.cancelled
+}
+
+// Why the import failed, if it did
+func (m Model) Err() error {
+	return m.err
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.progress.Width = min(maxBarWidth, max(10, msg.Width-4))
+		return m, nil
+	case tickMessage:
+		m.now = time.Time(msg)
+		return m, tick()
+	case resultMessage:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, m.waitForResult()
+		}
+
+		m.done++
+		m.lastAt = time.Now()
+		m.now = m.lastAt
+		m.last = msg.Title
+		switch {
+		case msg.Queued:
+			m.queued++
+		case msg.ID == 0:
+			m.unresolved++
+		default:
+			m.imported++
+		}
+		return m, tea.Batch(m.waitForResult(), m.progress.SetPercent(m.percent()))
+	case finishedMessage:
+		m.finished = true
+		m.cancelled = m.done < m.total && m.err == nil
+		return m, tea.Quit
+	case progress.FrameMsg:
+		p, cmd := m.progress.Update(msg)
+		m.progress = p.(progress.Model)
+		return m, cmd
+	case tea.KeyMsg:
+		if key.Matches(msg, ImporterKeyMap.Stop) && !m.stopping {
+			// The lookups already going still get saved, and the channel closes after them
+			m.stopping = true
+			m.cancel()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m Model) statusView() string {
+	counts := fmt.Sprintf("%d of %d", m.done, m.total)
+	switch {
+	case m.err != nil:
+		return errorStyle.Render("Import failed: " + m.err.Error())
+	case m.finished && m.cancelled:
+		return counts + dimStyle.Render(" · stopped, run the same import again to carry on")
+	case m.finished:
+		return counts + dimStyle.Render(" · done")
+	case m.stopping:
+		return counts + dimStyle.Render(" · stopping after the lookups in progress…")
+	}
+
+	left, ok := eta(m.done-m.resumed, m.total-m.done, m.lastAt.Sub(m.started))
+	if !ok {
+		return counts + dimStyle.Render(" · working out how long this will take")
+	}
+	return counts + dimStyle.Render(" · "+etaView(max(0, left-m.now.Sub(m.lastAt))))
+}
+
+func (m Model) View() string {
+	view := titleStyle.Render("Importing from Kitsu") + "\n\n"
+	if m.finished {
+		view += m.progress.ViewAs(m.percent())
+	} else {
+		view += m.progress.View()
+	}
+	view += "\n" + m.statusView() + "\n"
+
+	if m.last != "" {
+		view += dimStyle.Render(fmt.Sprintf("%d added, %d without a MAL match, %d waiting until we're online", m.imported, m.unresolved, m.queued)) + "\n"
+		view += dimStyle.Render("Last: "+m.last) + "\n"
+	}
+	if !m.finished {
+		view += "\n" + m.help.View(ImporterKeyMap) + "\n"
+	}
+	return lipgloss.NewStyle().MaxWidth(max(m.width, maxBarWidth)).Render(view)
+}
@@ -0,0 +1,64 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/jikan"
+)
+
+const kitsuXml = `<?xml version="1.0" encoding="UTF-8" ?>
+<myanimelist>
+	<anime>
+		<series_animedb_id>1</series_animedb_id>
+		<series_title><![CDATA[Cowboy Bebop]]></series_title>
+		<my_status>Completed</my_status>
+	</anime>
+	<anime>
+		<series_animedb_id>0</series_animedb_id>
+		<series_title><![CDATA[Some Kitsu Only Short]]></series_title>
+		<my_status>Watching</my_status>
+	</anime>
+</myanimelist>`
+
+// Offline lookups come back straight away, so entries are being saved while New is still setting up. Run with -race to check that's safe
+func TestNewOffline(t *testing.T) {
+	cfg, err := db.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := db.LoadMigrations(os.DirFS("../sql/schema"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Migrate(migrations); err != nil {
+		t.Fatal(err)
+	}
+
+	client := jikan.New("http://localhost:0")
+	client.SetOffline(true)
+	kitsu, err := cfg.NewKitsuImport([]byte(kitsuXml), client, filepath.Join(t.TempDir(), "kitsu-import.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m tea.Model = New(kitsu)
+	for !m.(Model).finished {
+		m, _ = m.Update(m.(Model).waitForResult()())
+	}
+
+	final := m.(Model)
+	if final.Err() != nil {
+		t.Fatal(final.Err())
+	}
+	if final.done != 2 || final.total != 2 || final.Cancelled() {
+		t.Fatalf("expected both entries done once, got %d of %d (cancelled %v)", final.done, final.total, final.Cancelled())
+	}
+	if final.imported != 1 || final.queued != 1 {
+		t.Fatalf("expected 1 imported and 1 queued, got %d and %d", final.imported, final.queued)
+	}
+}
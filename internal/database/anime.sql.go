@@ -7,20 +7,25 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createAnime = `-- name: CreateAnime :one
-INSERT INTO anime (id, title, startDate, updatedDate, completion)
-VALUES (?, ?, ?, ?, ?)
-RETURNING id, title, startdate, updateddate, completion
+INSERT INTO anime (id, title, startDate, updatedDate, completion, watchedEpisodes, score, finishDate, timesWatched)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, title, startdate, updateddate, completion, watchedepisodes, score, finishdate, timeswatched
 `
 
 type CreateAnimeParams struct {
-	ID          int64
-	Title       string
-	Startdate   string
-	Updateddate string
-	Completion  string
+	ID              int64
+	Title           string
+	Startdate       sql.NullString
+	Updateddate     string
+	Completion      string
+	Watchedepisodes int64
+	Score           int64
+	Finishdate      sql.NullString
+	Timeswatched    int64
 }
 
 func (q *Queries) CreateAnime(ctx context.Context, arg CreateAnimeParams) (Anime, error) {
@@ -30,6 +35,10 @@ func (q *Queries) CreateAnime(ctx context.Context, arg CreateAnimeParams) (Anime
 		arg.Startdate,
 		arg.Updateddate,
 		arg.Completion,
+		arg.Watchedepisodes,
+		arg.Score,
+		arg.Finishdate,
+		arg.Timeswatched,
 	)
 	var i Anime
 	err := row.Scan(
@@ -38,6 +47,10 @@ func (q *Queries) CreateAnime(ctx context.Context, arg CreateAnimeParams) (Anime
 		&i.Startdate,
 		&i.Updateddate,
 		&i.Completion,
+		&i.Watchedepisodes,
+		&i.Score,
+		&i.Finishdate,
+		&i.Timeswatched,
 	)
 	return i, err
 }
@@ -61,7 +74,7 @@ func (q *Queries) DeleteAnime(ctx context.Context, id int64) error {
 }
 
 const getAllAnime = `-- name: GetAllAnime :many
-SELECT id, title, startdate, updateddate, completion FROM anime
+SELECT id, title, startdate, updateddate, completion, watchedepisodes, score, finishdate, timeswatched FROM anime
 `
 
 func (q *Queries) GetAllAnime(ctx context.Context) ([]Anime, error) {
@@ -79,6 +92,10 @@ func (q *Queries) GetAllAnime(ctx context.Context) ([]Anime, error) {
 			&i.Startdate,
 			&i.Updateddate,
 			&i.Completion,
+			&i.Watchedepisodes,
+			&i.Score,
+			&i.Finishdate,
+			&i.Timeswatched,
 		); err != nil {
 			return nil, err
 		}
@@ -94,7 +111,7 @@ func (q *Queries) GetAllAnime(ctx context.Context) ([]Anime, error) {
 }
 
 const getAnime = `-- name: GetAnime :one
-SELECT id, title, startdate, updateddate, completion FROM anime
+SELECT id, title, startdate, updateddate, completion, watchedepisodes, score, finishdate, timeswatched FROM anime
 WHERE id = ? LIMIT 1
 `
 
@@ -107,19 +124,27 @@ func (q *Queries) GetAnime(ctx context.Context, id int64) (Anime, error) {
 		&i.Startdate,
 		&i.Updateddate,
 		&i.Completion,
+		&i.Watchedepisodes,
+		&i.Score,
+		&i.Finishdate,
+		&i.Timeswatched,
 	)
 	return i, err
 }
 
 const updateAnime = `-- name: UpdateAnime :exec
-UPDATE anime SET startDate = ?, updatedDate = ?, completion = ? WHERE id = ?
+UPDATE anime SET startDate = ?, updatedDate = ?, completion = ?, watchedEpisodes = ?, score = ?, finishDate = ?, timesWatched = ? WHERE id = ?
 `
 
 type UpdateAnimeParams struct {
-	Startdate   string
-	Updateddate string
-	Completion  string
-	ID          int64
+	Startdate       sql.NullString
+	Updateddate     string
+	Completion      string
+	Watchedepisodes int64
+	Score           int64
+	Finishdate      sql.NullString
+	Timeswatched    int64
+	ID              int64
 }
 
 func (q *Queries) UpdateAnime(ctx context.Context, arg UpdateAnimeParams) error {
@@ -127,7 +152,101 @@ func (q *Queries) UpdateAnime(ctx context.Context, arg UpdateAnimeParams) error
 		arg.Startdate,
 		arg.Updateddate,
 		arg.Completion,
+		arg.Watchedepisodes,
+		arg.Score,
+		arg.Finishdate,
+		arg.Timeswatched,
 		arg.ID,
 	)
 	return err
 }
+
+const updateAnimeCompletion = `-- name: UpdateAnimeCompletion :exec
+UPDATE anime SET completion = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeCompletionParams struct {
+	Completion  string
+	Updateddate string
+	ID          int64
+}
+
+func (q *Queries) UpdateAnimeCompletion(ctx context.Context, arg UpdateAnimeCompletionParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeCompletion, arg.Completion, arg.Updateddate, arg.ID)
+	return err
+}
+
+const updateAnimeEpisodes = `-- name: UpdateAnimeEpisodes :exec
+UPDATE anime SET watchedEpisodes = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeEpisodesParams struct {
+	Watchedepisodes int64
+	Updateddate     string
+	ID              int64
+}
+
+func (q *Queries) UpdateAnimeEpisodes(ctx context.Context, arg UpdateAnimeEpisodesParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeEpisodes, arg.Watchedepisodes, arg.Updateddate, arg.ID)
+	return err
+}
+
+const updateAnimeFinishDate = `-- name: UpdateAnimeFinishDate :exec
+UPDATE anime SET finishDate = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeFinishDateParams struct {
+	Finishdate  sql.NullString
+	Updateddate string
+	ID          int64
+}
+
+func (q *Queries) UpdateAnimeFinishDate(ctx context.Context, arg UpdateAnimeFinishDateParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeFinishDate, arg.Finishdate, arg.Updateddate, arg.ID)
+	return err
+}
+
+const updateAnimeScore = `-- name: UpdateAnimeScore :exec
+UPDATE anime SET score = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeScoreParams struct {
+	Score       int64
+	Updateddate string
+	ID          int64
+}
+
+func (q *Queries) UpdateAnimeScore(ctx context.Context, arg UpdateAnimeScoreParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeScore, arg.Score, arg.Updateddate, arg.ID)
+	return err
+}
+
+const updateAnimeStartDate = `-- name: UpdateAnimeStartDate :exec
+UPDATE anime SET startDate = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeStartDateParams struct {
+	Startdate   sql.NullString
+	Updateddate string
+	ID          int64
+}
+
+func (q *Queries) UpdateAnimeStartDate(ctx context.Context, arg UpdateAnimeStartDateParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeStartDate, arg.Startdate, arg.Updateddate, arg.ID)
+	return err
+}
+
+const updateAnimeTimesWatched = `-- name: UpdateAnimeTimesWatched :exec
+UPDATE anime SET timesWatched = ?, updatedDate = ? WHERE id = ?
+`
+
+type UpdateAnimeTimesWatchedParams struct {
+	Timeswatched int64
+	Updateddate  string
+	ID           int64
+}
+
+func (q *Queries) UpdateAnimeTimesWatched(ctx context.Context, arg UpdateAnimeTimesWatchedParams) error {
+	_, err := q.db.ExecContext(ctx, updateAnimeTimesWatched, arg.Timeswatched, arg.Updateddate, arg.ID)
+	return err
+}
@@ -4,10 +4,65 @@
 
 package database
 
+import (
+	"database/sql"
+)
+
 type Anime struct {
+	ID              int64
+	Title           string
+	Startdate       sql.NullString
+	Updateddate     string
+	Completion      string
+	Watchedepisodes int64
+	Score           int64
+	Finishdate      sql.NullString
+	Timeswatched    int64
+}
+
+type AnimeSearch struct {
+	Title       string
+	Othertitles string
+	Synopsis    string
+}
+
+type AnimeSearchSource struct {
 	ID          int64
 	Title       string
-	Startdate   string
-	Updateddate string
-	Completion  string
+	Othertitles string
+	Synopsis    string
+}
+
+type Metadatum struct {
+	ID            int64
+	Title         string
+	Titleenglish  string
+	Titlejapanese string
+	Synonyms      string
+	Synopsis      string
+	Type          string
+	Episodes      int64
+	Status        string
+	Airing        bool
+	Airedfrom     sql.NullString
+	Airedto       sql.NullString
+	Genres        string
+	Studios       string
+	Imageurl      string
+	Data          string
+	Fetchedat     string
+}
+
+type PendingLookup struct {
+	ID              int64
+	Title           string
+	Name            string
+	Link            string
+	Completion      string
+	Queuedat        string
+	Watchedepisodes int64
+	Score           int64
+	Timeswatched    int64
+	Startdate       sql.NullString
+	Finishdate      sql.NullString
 }
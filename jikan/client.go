@@ -0,0 +1,231 @@
+package jikan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saubuny/haru/types"
+)
+
+const DefaultBaseURL = "https://api.jikan.moe/v4"
+
+// Talks to the Jikan API (an unofficial MAL API). Safe to share between goroutines, and should be shared so every request goes through the same rate limiter
+type Client struct {
+	baseURL string
+	http    *http.Client
+	limiter *Limiter
+
+	// Rate limited and server errors are retried, waiting backoff*2^attempt between tries unless Jikan says how long with Retry-After. Either way it
+	// never waits longer than maxBackoff
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	// Either forced with SetOffline, or set for offlineCooldown after a request can't reach Jikan at all
+	mu              sync.Mutex
+	forcedOffline   bool
+	offlineUntil    time.Time
+	offlineCooldown time.Duration
+}
+
+// Creates a client for the Jikan API at baseURL, usually DefaultBaseURL
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		http:       &http.Client{Timeout: 10 * time.Second},
+		limiter:    NewLimiter(DefaultLimits...),
+		maxRetries: 4,
+		backoff:    time.Second,
+		maxBackoff: 30 * time.Second,
+
+		offlineCooldown: 30 * time.Second,
+	}
+}
+
+// Stops the client from making any requests until it's set back online. Only needed for --offline, since the client notices by itself when the network is gone
+func (c *Client) SetOffline(offline bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forcedOffline = offline
+	c.offlineUntil = time.Time{}
+}
+
+// Whether requests will fail with ErrOffline right now
+func (c *Client) Offline() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.forcedOffline || time.Now().Before(c.offlineUntil)
+}
+
+// Sets the client offline for a while after a request couldn't reach Jikan, so every other request doesn't have to time out too
+func (c *Client) setUnreachable(unreachable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if unreachable {
+		c.offlineUntil = time.Now().Add(c.offlineCooldown)
+	} else {
+		c.offlineUntil = time.Time{}
+	}
+}
+
+// Fetches the full details of a single anime
+func (c *Client) GetAnime(ctx context.Context, id int) (types.AnimeDataResponse, error) {
+	var anime types.AnimeDataResponse
+	err := c.get(ctx, "/anime/"+strconv.Itoa(id), nil, &anime)
+	return anime, err
+}
+
+// Searches anime by title, narrowed down by filters. The query can be empty to browse everything matching the filters. Pages start at 1
+func (c *Client) SearchAnime(ctx context.Context, query string, filters SearchFilters, page int) (types.AnimeListResponse, error) {
+	values := filters.values(query)
+	setPage(values, page)
+
+	var anime types.AnimeListResponse
+	err := c.get(ctx, "/anime", values, &anime)
+	return anime, err
+}
+
+// A page of the highest rated anime. Pages start at 1
+func (c *Client) TopAnime(ctx context.Context, page int) (types.AnimeListResponse, error) {
+	values := url.Values{}
+	setPage(values, page)
+
+	var anime types.AnimeListResponse
+	err := c.get(ctx, "/top/anime", values, &anime)
+	return anime, err
+}
+
+// A page of the anime airing in a season, which is "winter", "spring", "summer" or "fall". Pages start at 1
+func (c *Client) Season(ctx context.Context, year int, season string, page int) (types.AnimeListResponse, error) {
+	values := url.Values{}
+	setPage(values, page)
+
+	var anime types.AnimeListResponse
+	err := c.get(ctx, "/seasons/"+strconv.Itoa(year)+"/"+season, values, &anime)
+	return anime, err
+}
+
+// A page of the anime airing this season. Pages start at 1
+func (c *Client) SeasonNow(ctx context.Context, page int) (types.AnimeListResponse, error) {
+	values := url.Values{}
+	setPage(values, page)
+
+	var anime types.AnimeListResponse
+	err := c.get(ctx, "/seasons/now", values, &anime)
+	return anime, err
+}
+
+// The first page is the default, so it's left out
+func setPage(values url.Values, page int) {
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+}
+
+// Makes a rate limited GET request, retrying when Jikan is overloaded, and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	if c.Offline() {
+		return fmt.Errorf("jikan %s: %w", path, ErrOffline)
+	}
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.getOnce(ctx, endpoint, out)
+
+		var apiErr *APIError
+		if err != nil && !errors.As(err, &apiErr) {
+			return fmt.Errorf("jikan %s: %w", path, err)
+		}
+		if err == nil || attempt >= c.maxRetries || !apiErr.retryable() {
+			return err
+		}
+
+		wait := apiErr.RetryAfter
+		if wait == 0 {
+			wait = c.backoff << attempt
+		}
+		wait = min(c.maxBackoff, wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) getOnce(ctx context.Context, endpoint string, out any) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.setUnreachable(true)
+		return fmt.Errorf("%w: %w", ErrOffline, err)
+	}
+	defer res.Body.Close()
+	c.setUnreachable(false)
+
+	if res.StatusCode != http.StatusOK {
+		return newAPIError(res)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// Reads Jikan's error body, which looks like {"status": 404, "message": "Resource does not exist", ...}
+func newAPIError(res *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: res.StatusCode, RetryAfter: retryAfter(res.Header.Get("Retry-After"))}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	var jikanErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &jikanErr) == nil {
+		apiErr.Message = jikanErr.Message
+	}
+
+	return apiErr
+}
+
+// Retry-After is either a number of seconds or an HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
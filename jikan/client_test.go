@@ -0,0 +1,210 @@
+package jikan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	// Retries and rate limits would make the tests crawl otherwise
+	c := New(server.URL)
+	c.backoff = time.Millisecond
+	c.limiter = NewLimiter()
+	return c
+}
+
+func TestGetAnime(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/anime/1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data": {"mal_id": 1, "title": "Cowboy Bebop", "episodes": 26}}`))
+	})
+
+	anime, err := c.GetAnime(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anime.Data.MalID != 1 || anime.Data.Title != "Cowboy Bebop" || anime.Data.Episodes != 26 {
+		t.Fatalf("unexpected anime %#v", anime.Data)
+	}
+}
+
+func TestSearchAnimeEscapesQuery(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != "kaguya-sama: love & war" {
+			t.Errorf("query was mangled: %q", q)
+		}
+		w.Write([]byte(`{"data": [{"mal_id": 37999}], "pagination": {"has_next_page": true, "last_visible_page": 3}}`))
+	})
+
+	anime, err := c.SearchAnime(context.Background(), "kaguya-sama: love & war", SearchFilters{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anime.Data) != 1 || !anime.Pagination.HasNextPage || anime.Pagination.LastVisiblePage != 3 {
+		t.Fatalf("unexpected response %#v", anime)
+	}
+}
+
+func TestSearchAnimeFilters(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		expected := "end_date=2020-06&genres=1%2C22&limit=5&min_score=7.5&order_by=score&sort=desc&status=complete&type=tv"
+		if r.URL.RawQuery != expected {
+			t.Errorf("expected query %s, got %s", expected, r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"data": []}`))
+	})
+
+	filters := SearchFilters{Type: "tv", Status: "complete", Genres: []int{1, 22}, MinScore: 7.5, EndDate: "2020-06", OrderBy: "score", Sort: "desc", Limit: 5}
+	if !filters.Active() || (SearchFilters{}).Active() || (SearchFilters{Limit: 5}).Active() {
+		t.Fatal("Active should only be true when a filter is set")
+	}
+	if _, err := c.SearchAnime(context.Background(), "", filters, 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTopAnimePage(t *testing.T) {
+	pages := []string{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		pages = append(pages, r.URL.Query().Get("page"))
+		w.Write([]byte(`{"data": []}`))
+	})
+
+	for _, page := range []int{1, 3} {
+		if _, err := c.TopAnime(context.Background(), page); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first page doesn't need asking for
+	if pages[0] != "" || pages[1] != "3" {
+		t.Fatalf("unexpected pages requested %q", pages)
+	}
+}
+
+func TestSeason(t *testing.T) {
+	paths := []string{}
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.RequestURI())
+		w.Write([]byte(`{"data": []}`))
+	})
+
+	if _, err := c.Season(context.Background(), 2024, "fall", 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SeasonNow(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if paths[0] != "/seasons/2024/fall?page=2" || paths[1] != "/seasons/now" {
+		t.Fatalf("unexpected requests %q", paths)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+	}
+
+	for _, test := range tests {
+		c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			w.Write([]byte(`{"status": 0, "message": "Resource does not exist"}`))
+		})
+
+		_, err := c.GetAnime(context.Background(), 1)
+		if !errors.Is(err, test.expected) {
+			t.Fatalf("%d: expected %v, got %v", test.status, test.expected, err)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status || apiErr.Message != "Resource does not exist" {
+			t.Fatalf("%d: unexpected error %#v", test.status, err)
+		}
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	requests := 0
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data": {"mal_id": 1}}`))
+	})
+	c.maxBackoff = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := c.GetAnime(ctx, 1); err != nil {
+		t.Fatalf("expected the retry to wait maxBackoff rather than an hour, got %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestBadJSON(t *testing.T) {
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": `))
+	})
+
+	if _, err := c.TopAnime(context.Background(), 1); err == nil {
+		t.Fatal("expected an error decoding a broken response")
+	}
+}
+
+func TestOffline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"data": {"mal_id": 1}}`))
+	}))
+	c := New(server.URL)
+	c.limiter = NewLimiter()
+
+	// Nothing listening means no network
+	server.Close()
+	if _, err := c.GetAnime(context.Background(), 1); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline when the server is unreachable, got %v", err)
+	}
+	if !c.Offline() {
+		t.Fatal("expected the client to be offline after failing to connect")
+	}
+
+	c = newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"data": {"mal_id": 1}}`))
+	})
+	c.SetOffline(true)
+	if _, err := c.GetAnime(context.Background(), 1); !errors.Is(err, ErrOffline) {
+		t.Fatalf("expected ErrOffline while forced offline, got %v", err)
+	}
+	if requests != 0 {
+		t.Fatalf("expected no requests while offline, got %d", requests)
+	}
+
+	c.SetOffline(false)
+	if _, err := c.GetAnime(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if c.Offline() || requests != 1 {
+		t.Fatalf("expected one request once back online, got %d", requests)
+	}
+}
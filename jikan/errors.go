@@ -0,0 +1,49 @@
+package jikan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Use with errors.Is to check what kind of APIError came back
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrRateLimited = errors.New("rate limited")
+	ErrServer      = errors.New("jikan server error")
+
+	// Returned without making a request while the client is offline, and wrapped around the network error that made it go offline
+	ErrOffline = errors.New("offline")
+)
+
+// Any response from Jikan that wasn't a 200
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration // 0 if Jikan didn't say
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("jikan: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("jikan: %d %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// Whether trying again later could work
+func (e *APIError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
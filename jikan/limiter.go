@@ -0,0 +1,87 @@
+package jikan
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// At most Requests requests in any window of length Per
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// What Jikan allows: https://docs.api.jikan.moe/#section/Information/Rate-Limiting
+var DefaultLimits = []Limit{
+	{Requests: 3, Per: time.Second},
+	{Requests: 60, Per: time.Minute},
+}
+
+// A token bucket for every limit. A request has to take a token from all of them
+type Limiter struct {
+	mu      sync.Mutex
+	buckets []*bucket
+}
+
+type bucket struct {
+	tokens   float64
+	burst    float64
+	interval time.Duration // time to refill a single token
+	last     time.Time
+}
+
+func NewLimiter(limits ...Limit) *Limiter {
+	l := &Limiter{}
+	now := time.Now()
+	for _, limit := range limits {
+		l.buckets = append(l.buckets, &bucket{
+			tokens:   float64(limit.Requests),
+			burst:    float64(limit.Requests),
+			interval: limit.Per / time.Duration(limit.Requests),
+			last:     now,
+		})
+	}
+	return l
+}
+
+// Blocks until a request is allowed, or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve(time.Now())
+		if wait == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Takes a token from every bucket if they all have one, otherwise returns how long until they will
+func (l *Limiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	for _, b := range l.buckets {
+		b.tokens = min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.interval))
+		b.last = now
+		if b.tokens < 1 {
+			wait = max(wait, time.Duration((1-b.tokens)*float64(b.interval)))
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for _, b := range l.buckets {
+		b.tokens--
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package jikan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(Limit{Requests: 2, Per: 100 * time.Millisecond}, Limit{Requests: 3, Per: time.Hour})
+	now := time.Now()
+
+	if l.reserve(now) != 0 || l.reserve(now) != 0 {
+		t.Fatal("expected a burst of 2 to be allowed")
+	}
+	if wait := l.reserve(now); wait <= 0 || wait > 50*time.Millisecond {
+		t.Fatalf("expected to wait for the next token, got %v", wait)
+	}
+
+	// The first limit has refilled, but the hourly one only has a single token left
+	later := now.Add(time.Second)
+	if l.reserve(later) != 0 {
+		t.Fatal("expected a request after refilling")
+	}
+	if wait := l.reserve(later); wait < time.Minute {
+		t.Fatalf("expected the hourly limit to kick in, got %v", wait)
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(Limit{Requests: 1, Per: time.Hour})
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to be cancelled, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data": {"mal_id": 1}}`))
+	})
+
+	if _, err := c.GetAnime(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestRetriesGiveUp(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	if _, err := c.GetAnime(context.Background(), 1); !errors.Is(err, ErrServer) {
+		t.Fatalf("expected a server error, got %v", err)
+	}
+	if int(requests.Load()) != c.maxRetries+1 {
+		t.Fatalf("expected %d requests, got %d", c.maxRetries+1, requests.Load())
+	}
+
+	// Not found won't change by asking again
+	requests.Store(0)
+	c404 := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	c404.GetAnime(context.Background(), 1)
+	if requests.Load() != 1 {
+		t.Fatalf("expected a single request, got %d", requests.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter("3"); got != 3*time.Second {
+		t.Fatalf("expected 3s, got %v", got)
+	}
+	if got := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); got < 55*time.Second || got > time.Minute {
+		t.Fatalf("expected about a minute, got %v", got)
+	}
+	if got := retryAfter("soon"); got != 0 {
+		t.Fatalf("expected 0 for garbage, got %v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package jikan
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Narrows down a search. Anything left empty isn't sent, so the zero value searches everything
+type SearchFilters struct {
+	Type      string  `json:"type,omitempty"`
+	Status    string  `json:"status,omitempty"`
+	Rating    string  `json:"rating,omitempty"`
+	Genres    []int   `json:"genres,omitempty"`
+	MinScore  float64 `json:"min_score,omitempty"`
+	StartDate string  `json:"start_date,omitempty"` // YYYY, YYYY-MM or YYYY-MM-DD
+	EndDate   string  `json:"end_date,omitempty"`
+	OrderBy   string  `json:"order_by,omitempty"`
+	Sort      string  `json:"sort,omitempty"`
+
+	// Most results per page, or Jikan's default of 25 when 0. Only used for lookups, so it isn't saved with the rest
+	Limit int `json:"-"`
+}
+
+// Whether any filter is set
+func (f SearchFilters) Active() bool {
+	return f.Type != "" || f.Status != "" || f.Rating != "" || len(f.Genres) > 0 || f.MinScore > 0 ||
+		f.StartDate != "" || f.EndDate != "" || f.OrderBy != "" || f.Sort != ""
+}
+
+func (f SearchFilters) values(query string) url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	set("q", query)
+	set("type", f.Type)
+	set("status", f.Status)
+	set("rating", f.Rating)
+	set("start_date", f.StartDate)
+	set("end_date", f.EndDate)
+	set("order_by", f.OrderBy)
+	set("sort", f.Sort)
+	if f.Limit > 0 {
+		values.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.MinScore > 0 {
+		values.Set("min_score", strconv.FormatFloat(f.MinScore, 'f', -1, 64))
+	}
+
+	genres := []string{}
+	for _, id := range f.Genres {
+		genres = append(genres, strconv.Itoa(id))
+	}
+	set("genres", strings.Join(genres, ","))
+
+	return values
+}
+
+// One of the values Jikan accepts for a filter, and how to show it
+type Option struct {
+	Value string
+	Label string
+}
+
+// Everything Jikan accepts for each filter. Genres are MAL's IDs, which don't change, so there's no need to ask Jikan for them
+var (
+	Types = []Option{
+		{"tv", "TV"},
+		{"movie", "Movie"},
+		{"ova", "OVA"},
+		{"special", "Special"},
+		{"ona", "ONA"},
+		{"music", "Music"},
+		{"tv_special", "TV Special"},
+	}
+	Statuses = []Option{
+		{"airing", "Airing"},
+		{"complete", "Finished"},
+		{"upcoming", "Upcoming"},
+	}
+	Ratings = []Option{
+		{"g", "G - All Ages"},
+		{"pg", "PG - Children"},
+		{"pg13", "PG-13 - Teens 13 or older"},
+		{"r17", "R - 17+ (violence & profanity)"},
+		{"r", "R+ - Mild Nudity"},
+	}
+	Orders = []Option{
+		{"score", "Score"},
+		{"popularity", "Popularity"},
+		{"members", "Members"},
+		{"favorites", "Favorites"},
+		{"rank", "Rank"},
+		{"title", "Title"},
+		{"start_date", "Start date"},
+		{"end_date", "End date"},
+		{"episodes", "Episodes"},
+	}
+	Sorts = []Option{
+		{"desc", "Descending"},
+		{"asc", "Ascending"},
+	}
+	Genres = []Option{
+		{"1", "Action"},
+		{"2", "Adventure"},
+		{"5", "Avant Garde"},
+		{"46", "Award Winning"},
+		{"28", "Boys Love"},
+		{"4", "Comedy"},
+		{"8", "Drama"},
+		{"9", "Ecchi"},
+		{"10", "Fantasy"},
+		{"26", "Girls Love"},
+		{"47", "Gourmet"},
+		{"14", "Horror"},
+		{"7", "Mystery"},
+		{"22", "Romance"},
+		{"24", "Sci-Fi"},
+		{"36", "Slice of Life"},
+		{"30", "Sports"},
+		{"37", "Supernatural"},
+		{"41", "Suspense"},
+	}
+)
+
+// The label for value, or value itself if it isn't one of options
+func Label(options []Option, value string) string {
+	for _, o := range options {
+		if o.Value == value {
+			return o.Label
+		}
+	}
+	return value
+}
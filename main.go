@@ -1,43 +1,192 @@
 package main
 
 import (
-	_ "embed"
+	"embed"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/saubuny/haru/animelist"
+	"github.com/saubuny/haru/config"
 	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/importer"
+	"github.com/saubuny/haru/jikan"
 	"github.com/saubuny/haru/navstack"
 	"github.com/urfave/cli/v2"
 )
 
-//go:embed sql/schema/schema.sql
-var migrations string
+//go:embed sql/schema/*.sql
+var schemaFiles embed.FS
 
-func main() {
-	cfg, err := db.InitDB(migrations, "anime.db")
+//go:embed sql/search/search_index.sql
+var searchIndexSQL string
+
+// Numbered migration files, without the sql/schema prefix
+func migrationFiles() fs.FS {
+	migrations, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		log.Fatalf("Error reading migrations: %v", err)
+	}
+	return migrations
+}
+
+// Opens the database with an up to date schema, and the search index if SQLite has FTS5
+func openDB(location string) (db.DBConfig, error) {
+	cfg, err := db.InitDB(migrationFiles(), location)
+	if err != nil {
+		return db.DBConfig{}, err
+	}
+
+	if err := cfg.EnableSearchIndex(searchIndexSQL); err != nil {
+		cfg.Conn.Close()
+		return db.DBConfig{}, fmt.Errorf("setting up search index: %w", err)
+	}
+	return cfg, nil
+}
+
+// Imported entries that need adding by hand
+func printUnresolved(unresolved []string) {
+	if len(unresolved) == 0 {
+		return
+	}
+
+	log.Printf("Could not find a confident MAL match for %d entries, add these manually:", len(unresolved))
+	for _, entry := range unresolved {
+		log.Printf("  %s", entry)
+	}
+}
+
+// Kitsu imports look every entry up on MAL, so they show their progress while they run. Progress is saved as it goes, and running the same import
+// again after stopping it carries on from there
+func importKitsu(cfg db.DBConfig, file []byte, client *jikan.Client) ([]string, error) {
+	stateDir, err := config.StateDir()
 	if err != nil {
-		log.Fatalf("Error initalizing DB: %v", err)
+		return nil, err
 	}
 
+	kitsu, err := cfg.NewKitsuImport(file, client, filepath.Join(stateDir, "kitsu-import.json"))
+	if err != nil {
+		return nil, err
+	}
+	if kitsu.Done() > 0 {
+		log.Printf("Carrying on from last time, %d of %d entries were already imported", kitsu.Done(), kitsu.Total())
+	}
+
+	// Anything logged would be drawn over the progress bar
+	logFile, err := config.LogFile()
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+	log.SetOutput(logFile)
+	defer log.SetOutput(os.Stderr)
+
+	final, err := tea.NewProgram(importer.New(kitsu)).Run()
+	if err != nil {
+		return nil, err
+	}
+
+	m := final.(importer.Model)
+	if m.Err() != nil {
+		return nil, m.Err()
+	}
+	if m.Cancelled() {
+		return nil, cli.Exit("Import stopped, run the same command again to carry on", 1)
+	}
+	return kitsu.Unresolved(), nil
+}
+
+func main() {
+	var dbFlag string
+	var offlineFlag bool
+	var dbLocation string
+	client := jikan.New(jikan.DefaultBaseURL)
+
 	var importFile string
 	var importPlatform string
+	var exportFormat string
+	var exportFile string
+	var migrateStatus bool
+	var pruneAll bool
+	var pruneOlderThan time.Duration
+	var refreshAll bool
 
 	// Run TUI by default
 	app := &cli.App{
 		Name:  "Haru",
 		Usage: "Track anime",
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:        "db",
+				Usage:       "database file to use (defaults to $XDG_DATA_HOME/haru/anime.db)",
+				EnvVars:     []string{"HARU_DB"},
+				Destination: &dbFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "offline",
+				Usage:       "never use the network, only what's already in the database",
+				EnvVars:     []string{"HARU_OFFLINE"},
+				Destination: &offlineFlag,
+			},
+		},
+		Before: func(ctx *cli.Context) error {
+			client.SetOffline(offlineFlag)
+
+			settings, err := config.Load()
+			if err != nil {
+				return fmt.Errorf("reading config file: %w", err)
+			}
+
+			location, isDefault, err := settings.DBPath(dbFlag)
+			if err != nil {
+				return err
+			}
+
+			moved, err := config.PrepareDB(location, isDefault)
+			if err != nil {
+				return err
+			}
+			if moved {
+				log.Printf("Moved ./anime.db to %s", location)
+			}
+
+			dbLocation = location
+			return nil
+		},
 		Action: func(ctx *cli.Context) error {
-			m := animelist.InitialModel(cfg)
-			nav := navstack.New(m)
+			cfg, err := openDB(dbLocation)
+			if err != nil {
+				log.Fatalf("Error initalizing DB: %v", err)
+			}
+
+			// Anything logged while the TUI is up would be drawn over it, so send it to a file instead
+			logFile, err := config.LogFile()
+			if err != nil {
+				log.Fatalf("Error opening log file: %v", err)
+			}
+			defer logFile.Close()
+			log.SetOutput(logFile)
+			defer log.SetOutput(os.Stderr)
+
+			m := animelist.InitialModel(cfg, client)
+			nav := navstack.New(m).WithIndicator(func() string {
+				if client.Offline() {
+					return "offline"
+				}
+				return ""
+			})
 			p := tea.NewProgram(nav, tea.WithAltScreen())
 			tea.SetWindowTitle("Haru")
 			if _, err := p.Run(); err != nil {
-				log.Fatalf("Error: %v", err)
+				log.Printf("Error: %v", err)
+				return err
 			}
 			return nil
 		},
@@ -55,11 +204,11 @@ func main() {
 					},
 					&cli.StringFlag{
 						Name:        "platform",
-						Usage:       "platform to import from (must be one of Hianime or MAL)",
+						Usage:       "platform to import from (must be one of Hianime, MAL, AniList, Kitsu, or JSON/CSV from haru export)",
 						Destination: &importPlatform,
 						Required:    true,
 						Action: func(ctx *cli.Context, s string) error {
-							validPlatforms := []string{"hianime", "mal"}
+							validPlatforms := []string{"hianime", "mal", "anilist", "kitsu", "json", "csv"}
 							if !slices.Contains(validPlatforms, strings.ToLower(importPlatform)) {
 								return cli.Exit("Invalid platform", 1)
 							}
@@ -68,20 +217,44 @@ func main() {
 					},
 				},
 				Action: func(ctx *cli.Context) error {
+					cfg, err := openDB(dbLocation)
+					if err != nil {
+						return err
+					}
+
 					file, err := os.ReadFile(importFile)
 					if err != nil {
 						return err
 					}
 
+					unresolved := []string{}
 					if strings.ToLower(importPlatform) == "mal" {
 						err = cfg.ImportMAL(file)
 					} else if strings.ToLower(importPlatform) == "hianime" {
-						err = cfg.ImportHianime(file)
+						unresolved, err = cfg.ImportHianime(file, db.JikanResolver(client, db.DefaultResolveThreshold))
+					} else if strings.ToLower(importPlatform) == "anilist" {
+						unresolved, err = cfg.ImportAniList(file)
+					} else if strings.ToLower(importPlatform) == "kitsu" {
+						unresolved, err = importKitsu(cfg, file, client)
+					} else if strings.ToLower(importPlatform) == "json" {
+						err = cfg.ImportJSON(file)
+					} else if strings.ToLower(importPlatform) == "csv" {
+						err = cfg.ImportCSV(file)
 					}
 					if err != nil {
 						return err
 					}
 
+					printUnresolved(unresolved)
+
+					pending, err := cfg.PendingLookupCount()
+					if err != nil {
+						return err
+					}
+					if pending > 0 {
+						log.Printf("%d entries couldn't be looked up while offline, they'll be added next time haru runs online (or with haru cache refresh)", pending)
+					}
+
 					anime, err := cfg.DB.GetAllAnime(cfg.Ctx) // TMP
 					if err != nil {
 						return err
@@ -91,6 +264,227 @@ func main() {
 					return nil
 				},
 			},
+			{
+				Name:    "export",
+				Aliases: []string{"e"},
+				Usage:   "export the list for another tracking platform",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "format",
+						Usage:       "format to export as (must be one of MAL, JSON or CSV)",
+						Destination: &exportFormat,
+						Required:    true,
+						Action: func(ctx *cli.Context, s string) error {
+							validFormats := []string{"mal", "json", "csv"}
+							if !slices.Contains(validFormats, strings.ToLower(exportFormat)) {
+								return cli.Exit("Invalid format", 1)
+							}
+							return nil
+						},
+					},
+					&cli.PathFlag{
+						Name:        "output",
+						Aliases:     []string{"o"},
+						Usage:       "file to write to (defaults to stdout)",
+						Destination: &exportFile,
+					},
+				},
+				Action: func(ctx *cli.Context) error {
+					cfg, err := openDB(dbLocation)
+					if err != nil {
+						return err
+					}
+
+					out := os.Stdout
+					if exportFile != "" {
+						out, err = os.Create(exportFile)
+						if err != nil {
+							return err
+						}
+						defer out.Close()
+					}
+
+					switch strings.ToLower(exportFormat) {
+					case "mal":
+						err = cfg.ExportMAL(out)
+					case "json":
+						err = cfg.ExportJSON(out)
+					case "csv":
+						err = cfg.ExportCSV(out)
+					}
+					if err != nil {
+						return err
+					}
+					if exportFile != "" {
+						return out.Close()
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "db",
+				Usage: "manage the local database",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "migrate",
+						Usage: "bring the database schema up to date",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:        "status",
+								Usage:       "only show which migrations have been applied",
+								Destination: &migrateStatus,
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							migrations, err := db.LoadMigrations(migrationFiles())
+							if err != nil {
+								return err
+							}
+
+							cfg, err := db.Open(dbLocation)
+							if err != nil {
+								return err
+							}
+							defer cfg.Conn.Close()
+
+							if !migrateStatus {
+								if err := cfg.Migrate(migrations); err != nil {
+									return err
+								}
+							}
+
+							statuses, err := cfg.MigrationStatus(migrations)
+							if err != nil {
+								return err
+							}
+
+							version, err := cfg.SchemaVersion()
+							if err != nil {
+								return err
+							}
+
+							fmt.Printf("Schema version %d (latest is %d)\n", version, len(migrations))
+							for _, status := range statuses {
+								mark := " "
+								if status.Applied {
+									mark = "x"
+								}
+								fmt.Printf("  [%s] %04d %s\n", mark, status.Version, status.Name)
+							}
+							return nil
+						},
+					},
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "manage cached anime details",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "prune",
+						Usage: "delete cached details that haven't been fetched in a while",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:        "all",
+								Usage:       "delete everything in the cache",
+								Destination: &pruneAll,
+							},
+							&cli.DurationFlag{
+								Name:        "older-than",
+								Usage:       "delete details fetched longer ago than this",
+								Value:       db.DefaultCacheTTL,
+								Destination: &pruneOlderThan,
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							cfg, err := openDB(dbLocation)
+							if err != nil {
+								return err
+							}
+							defer cfg.Conn.Close()
+
+							olderThan := pruneOlderThan
+							if pruneAll {
+								olderThan = 0
+							}
+
+							pruned, err := cfg.PruneCache(olderThan)
+							if err != nil {
+								return err
+							}
+
+							fmt.Printf("Pruned %d cached anime\n", pruned)
+							return nil
+						},
+					},
+					{
+						Name:  "refresh",
+						Usage: "fetch details for listed anime that are stale or not cached yet, and look up anything imported while offline",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:        "all",
+								Usage:       "refetch every listed anime, even if its details are fresh",
+								Destination: &refreshAll,
+							},
+						},
+						Action: func(ctx *cli.Context) error {
+							cfg, err := openDB(dbLocation)
+							if err != nil {
+								return err
+							}
+							defer cfg.Conn.Close()
+
+							if client.Offline() {
+								return cli.Exit("Can't refresh the cache while offline", 1)
+							}
+
+							added, unresolved, err := cfg.RetryPendingLookups(db.JikanResolver(client, db.DefaultResolveThreshold))
+							if err != nil {
+								return err
+							}
+							if added > 0 {
+								fmt.Printf("Added %d anime that were queued while offline\n", added)
+							}
+							printUnresolved(unresolved)
+
+							ids, err := cfg.StaleCacheIDs(db.DefaultCacheTTL)
+							if err != nil {
+								return err
+							}
+							if refreshAll {
+								anime, err := cfg.DB.GetAllAnime(cfg.Ctx)
+								if err != nil {
+									return err
+								}
+								ids = ids[:0]
+								for _, a := range anime {
+									ids = append(ids, a.ID)
+								}
+							}
+
+							failed := 0
+							for i, id := range ids {
+								anime, err := client.GetAnime(ctx.Context, int(id))
+								if err == nil {
+									err = cfg.CacheAnime(anime.Data)
+								}
+								if err != nil {
+									failed++
+									log.Printf("Could not refresh anime %d: %v", id, err)
+									continue
+								}
+								fmt.Printf("[%d/%d] %s\n", i+1, len(ids), anime.Data.Title)
+							}
+
+							fmt.Printf("Refreshed %d cached anime\n", len(ids)-failed)
+							if failed > 0 {
+								return cli.Exit(fmt.Sprintf("%d anime could not be refreshed", failed), 1)
+							}
+							return nil
+						},
+					},
+				},
+			},
 		},
 	}
 
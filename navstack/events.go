@@ -7,3 +7,6 @@ type PopNavigation struct{}
 type PushNavigation struct {
 	Item tea.Model
 }
+
+// Sent to the item left on top after a pop, so it can pick up anything the popped item changed
+type ResumeNavigation struct{}
@@ -4,6 +4,8 @@ package navstack
 
 import (
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/types"
 )
 
 func New(model tea.Model) Model {
@@ -18,7 +20,18 @@ func Cmd(msg interface{}) tea.Cmd {
 }
 
 type Model struct {
-	stack []tea.Model
+	stack  []tea.Model
+	status status
+	width  int
+
+	// Checked on every render, and shown before the status whenever it isn't empty
+	indicator func() string
+}
+
+// Shows whatever indicator returns at the start of the status bar, e.g. to say that we're offline
+func (m Model) WithIndicator(indicator func() string) Model {
+	m.indicator = indicator
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
@@ -49,7 +62,7 @@ func (m *Model) Pop() tea.Cmd {
 	}
 
 	m.stack = m.stack[:len(m.stack)-1]
-	return nil
+	return tea.Batch(Cmd(ResumeNavigation{}), tea.WindowSize())
 }
 
 // Returns the top item on the stack
@@ -62,19 +75,55 @@ func (m Model) Top() tea.Model {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	top := m.Top()
 	switch msg := msg.(type) {
 	case PopNavigation:
+		// A retry is for the screen that failed, so it goes when the screen does
+		m.status.retry = nil
 		return m, m.Pop()
 	case PushNavigation:
+		m.status.retry = nil
 		return m, m.Push(msg.Item)
+	case tea.WindowSizeMsg:
+		// Leave the last line for the status bar
+		m.width = msg.Width
+		msg.Height = max(0, msg.Height-1)
+		return m.updateTop(msg)
+	case Notify:
+		return m, m.setStatus(string(msg), false, nil)
+	case clearStatus:
+		// The retry stays, since the screen that failed might still be offering it
+		if int(msg) == m.status.id {
+			m.status = status{id: m.status.id, retry: m.status.retry}
+		}
+		return m, nil
+	case RetryableError:
+		// Screens only need to know that something failed
+		statusCmd := m.setStatus(string(msg.Err), true, msg.Retry)
+		updated, cmd := m.updateTop(msg.Err)
+		return updated, tea.Batch(statusCmd, cmd)
+	case types.ErrorMsg:
+		statusCmd := m.setStatus(string(msg), true, nil)
+		updated, cmd := m.updateTop(msg)
+		return updated, tea.Batch(statusCmd, cmd)
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "ctrl+r":
+			if m.status.retry != nil {
+				retry := m.status.retry
+				m.status = status{id: m.status.id}
+				return m, retry
+			}
 		}
 	}
 
+	return m.updateTop(msg)
+}
+
+func (m Model) updateTop(msg tea.Msg) (tea.Model, tea.Cmd) {
+	top := m.Top()
+
 	if top == nil {
 		return m, nil
 	}
@@ -90,5 +139,5 @@ func (m Model) View() string {
 		return ""
 	}
 
-	return top.View()
+	return top.View() + "\n" + lipgloss.NewStyle().MaxWidth(m.width).Render(m.statusView())
 }
@@ -0,0 +1,95 @@
+package navstack
+
+import (
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/types"
+)
+
+const (
+	errorTimeout  = 8 * time.Second
+	noticeTimeout = 3 * time.Second
+)
+
+var (
+	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	noticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	hintStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	indicatorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+)
+
+// Shows a short success message in the status bar
+type Notify string
+
+// An error that can be retried with ctrl+r. Made by commands wrapped in Retryable
+type RetryableError struct {
+	Err   types.ErrorMsg
+	Retry tea.Cmd
+}
+
+// Clears the status bar, as long as nothing newer has been shown since
+type clearStatus int
+
+// Wraps cmd so that if it fails, the error shown in the status bar can be retried
+func Retryable(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+		if err, ok := msg.(types.ErrorMsg); ok {
+			return RetryableError{Err: err, Retry: Retryable(cmd)}
+		}
+		return msg
+	}
+}
+
+type status struct {
+	id   int
+	text string
+	err  bool
+	// Outlasts the text, until it's used or replaced by another status
+	retry tea.Cmd
+}
+
+func (m *Model) setStatus(text string, isErr bool, retry tea.Cmd) tea.Cmd {
+	m.status.id++
+	m.status.text = text
+	m.status.err = isErr
+	m.status.retry = retry
+
+	timeout := noticeTimeout
+	if isErr {
+		log.Printf("Error: %s", text)
+		timeout = errorTimeout
+	}
+
+	id := m.status.id
+	return tea.Tick(timeout, func(time.Time) tea.Msg {
+		return clearStatus(id)
+	})
+}
+
+func (m Model) statusView() string {
+	indicator := ""
+	if m.indicator != nil {
+		if text := m.indicator(); text != "" {
+			indicator = indicatorStyle.Render("["+text+"]") + " "
+		}
+	}
+
+	return indicator + m.statusText()
+}
+
+func (m Model) statusText() string {
+	switch {
+	case m.status.text == "":
+		return ""
+	case !m.status.err:
+		return noticeStyle.Render(m.status.text)
+	case m.status.retry != nil:
+		return errorStyle.Render("Error: "+m.status.text) + hintStyle.Render("  ctrl+r to retry")
+	}
+	return errorStyle.Render("Error: " + m.status.text)
+}
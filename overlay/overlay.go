@@ -0,0 +1,114 @@
+package overlay
+
+// Based on https://gist.github.com/Broderick-Westrope/b89b14770c09dda928c4a108f437b927, simplified down to what haru needs
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+)
+
+const reset = "\x1b[0m"
+
+// Draws fg over the middle of bg. Both can contain ANSI styling
+func Center(fg string, bg string) string {
+	x := (lipgloss.Width(bg) - lipgloss.Width(fg)) / 2
+	y := (lipgloss.Height(bg) - lipgloss.Height(fg)) / 2
+	return Place(x, y, fg, bg)
+}
+
+// Draws fg over bg with its top left corner at column x and row y. Anything below the last line of bg is cut off
+func Place(x int, y int, fg string, bg string) string {
+	x, y = max(x, 0), max(y, 0)
+	bgLines := strings.Split(bg, "\n")
+	fgLines := strings.Split(fg, "\n")
+	fgWidth := lipgloss.Width(fg)
+
+	for i, fgLine := range fgLines {
+		row := y + i
+		if row >= len(bgLines) {
+			break
+		}
+
+		bgLine := bgLines[row]
+		bgWidth := ansi.StringWidth(bgLine)
+
+		// Pad short lines so the overlay still lands in the right column
+		if bgWidth < x+fgWidth {
+			bgLine += strings.Repeat(" ", x+fgWidth-bgWidth)
+		}
+
+		// Every fg line is padded to the same width so ragged lines don't let bg show through
+		fgLine += strings.Repeat(" ", fgWidth-ansi.StringWidth(fgLine))
+
+		left := ansi.Truncate(bgLine, x, "")
+		right := cutLeft(bgLine, x+fgWidth)
+		bgLines[row] = left + reset + fgLine + reset + right
+	}
+
+	return strings.Join(bgLines, "\n")
+}
+
+// Removes the first n cells of s, keeping any escape sequences so the rest of the line is still styled correctly
+func cutLeft(s string, n int) string {
+	var b strings.Builder
+	width := 0
+
+	for i := 0; i < len(s); {
+		// Copy escape sequences through as-is
+		if s[i] == '\x1b' {
+			end := escapeEnd(s, i)
+			b.WriteString(s[i:end])
+			i = end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		w := runewidth.RuneWidth(r)
+		switch {
+		case width >= n:
+			b.WriteString(s[i : i+size])
+		case width+w > n:
+			// A wide character cut in half gets replaced with spaces
+			b.WriteString(strings.Repeat(" ", width+w-n))
+		}
+		width += w
+		i += size
+	}
+
+	return b.String()
+}
+
+// Index just past the escape sequence starting at i
+func escapeEnd(s string, i int) int {
+	if i+1 >= len(s) {
+		return len(s)
+	}
+
+	switch s[i+1] {
+	case '[':
+		// CSI: parameters, then a single final byte from @ to ~
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j + 1
+			}
+		}
+		return len(s)
+	case ']':
+		// OSC: ends with BEL or ESC \
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return j + 1
+			}
+			if s[j] == '\x1b' && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 2
+			}
+		}
+		return len(s)
+	}
+
+	return i + 2
+}
@@ -0,0 +1,55 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCenter(t *testing.T) {
+	bg := "..........\n..........\n..........\n.........."
+	fg := "ab\ncd"
+
+	expected := "..........\n....ab....\n....cd....\n.........."
+	if got := ansi.Strip(Center(fg, bg)); got != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestPlaceShortBackground(t *testing.T) {
+	bg := "xx\nxxxxxxxx"
+	fg := "abc\nd"
+
+	// Short bg lines get padded, ragged fg lines get squared off, and anything past the last bg line is dropped
+	expected := "xx   abc\nxxxxxd  "
+	if got := ansi.Strip(Place(5, 0, fg, bg)); got != expected {
+		t.Fatalf("expected:\n%q\ngot:\n%q", expected, got)
+	}
+
+	if got := ansi.Strip(Place(0, 1, "a\nb\nc", bg)); got != "xx\naxxxxxxx" {
+		t.Fatalf("expected overlay to be cut off at the bottom, got %q", got)
+	}
+}
+
+func TestPlaceKeepsStyling(t *testing.T) {
+	red := "\x1b[31m"
+	bg := red + "0123456789" + reset
+
+	got := Place(2, 0, "ab", bg)
+	if ansi.Strip(got) != "01ab456789" {
+		t.Fatalf("wrong text: %q", ansi.Strip(got))
+	}
+
+	// The right hand side of the line should still be red
+	expectedRight := red + "456789" + reset
+	if got[len(got)-len(expectedRight):] != expectedRight {
+		t.Fatalf("styling was lost: %q", got)
+	}
+}
+
+func TestCutLeftWideCharacters(t *testing.T) {
+	// Each of these takes up 2 cells, so cutting 3 splits the second one in half
+	if got := cutLeft("日本語", 3); got != " 語" {
+		t.Fatalf("expected half cut character to become a space, got %q", got)
+	}
+}
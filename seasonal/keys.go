@@ -0,0 +1,80 @@
+package seasonal
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type KeyMap struct {
+	Up         key.Binding
+	Down       key.Binding
+	NextGroup  key.Binding
+	PrevGroup  key.Binding
+	NextSeason key.Binding
+	PrevSeason key.Binding
+	ThisSeason key.Binding
+	Select     key.Binding
+	Add        key.Binding
+	Esc        key.Binding
+	Help       key.Binding
+}
+
+// ShortHelp implements the KeyMap interface.
+func (km KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Up, km.Down}
+}
+
+// FullHelp implements the KeyMap interface.
+func (km KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Up, km.Down, km.Esc, km.Help},
+		{km.NextGroup, km.PrevGroup, km.Select, km.Add},
+		{km.NextSeason, km.PrevSeason, km.ThisSeason},
+	}
+}
+
+var SeasonalKeyMap = KeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("k", "up"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("j", "down"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	NextGroup: key.NewBinding(
+		key.WithKeys("tab", "l", "right"),
+		key.WithHelp("tab/→", "next type"),
+	),
+	PrevGroup: key.NewBinding(
+		key.WithKeys("shift+tab", "h", "left"),
+		key.WithHelp("shift+tab/←", "previous type"),
+	),
+	NextSeason: key.NewBinding(
+		key.WithKeys("]", ">"),
+		key.WithHelp("]", "next season"),
+	),
+	PrevSeason: key.NewBinding(
+		key.WithKeys("[", "<"),
+		key.WithHelp("[", "previous season"),
+	),
+	ThisSeason: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "this season"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "show info"),
+	),
+	Add: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "plan to watch"),
+	),
+	Esc: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "go back"),
+	),
+	Help: key.NewBinding(
+		key.WithKeys("?"),
+		key.WithHelp("?", "toggle help"),
+	),
+}
@@ -0,0 +1,399 @@
+package seasonal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/saubuny/haru/animeinfo"
+	"github.com/saubuny/haru/db"
+	"github.com/saubuny/haru/jikan"
+	"github.com/saubuny/haru/navstack"
+	"github.com/saubuny/haru/types"
+)
+
+// Everything airing in a season, split into tabs by type. Jikan gives seasons 25 anime at a time, so every page is loaded before
+// anything is shown, otherwise the groups would keep changing
+
+var (
+	baseStyle     = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240"))
+	seasonStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	groupStyle    = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("240"))
+	selectedGroup = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+)
+
+// Most pages loaded for one season, in case Jikan keeps saying there's another page. Anything past this is left out, and the season is marked as
+// incomplete
+const maxPages = 100
+
+// Every anime airing in a season
+type seasonMessage struct {
+	season    Season
+	anime     []types.AnimeData
+	truncated bool // there were more pages than maxPages
+}
+
+// MAL IDs in the list and their completion, to show what's already been added
+type listedMessage map[int64]string
+
+type addedMessage struct {
+	title string
+}
+
+type Model struct {
+	width  int
+	height int
+
+	dbConfig db.DBConfig
+	jikan    *jikan.Client
+
+	season  Season
+	current Season
+	groups  []group
+	group   int
+	listed  map[int64]string
+	// Only the first maxPages pages of the season were loaded
+	truncated bool
+
+	table   table.Model
+	help    help.Model
+	spinner spinner.Model
+
+	showHelp    bool
+	showSpinner bool
+}
+
+// Browser for the season airing right now
+func New(db db.DBConfig, client *jikan.Client) Model {
+	tb := table.New(
+		table.WithColumns(columns()),
+		table.WithFocused(true),
+	)
+	tbStyle := table.DefaultStyles()
+	tbStyle.Header = tbStyle.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		BorderBottom(true).
+		Bold(false)
+	tbStyle.Selected = tbStyle.Selected.
+		Foreground(lipgloss.Color("229")).
+		Background(lipgloss.Color("57")).
+		Bold(false)
+	tb.SetStyles(tbStyle)
+
+	help := help.New()
+	help.ShowAll = true
+
+	s := spinner.New()
+	s.Spinner = spinner.Points
+	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+
+	current := SeasonOf(time.Now())
+	return Model{
+		dbConfig:    db,
+		jikan:       client,
+		season:      current,
+		current:     current,
+		table:       tb,
+		help:        help,
+		spinner:     s,
+		showHelp:    true,
+		showSpinner: true,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.spinner.Tick, m.loadSeasonCmd(m.season), m.loadListed)
+}
+
+// Loads every page of a season. This season goes through /seasons/now, which leaves out anything that finished early
+func (m Model) loadSeasonCmd(season Season) tea.Cmd {
+	return navstack.Retryable(func() tea.Msg {
+		anime := []types.AnimeData{}
+		truncated := false
+		for page := 1; ; page++ {
+			var res types.AnimeListResponse
+			var err error
+			if season == m.current {
+				res, err = m.jikan.SeasonNow(context.Background(), page)
+			} else {
+				res, err = m.jikan.Season(context.Background(), season.Year, season.Name, page)
+			}
+			if errors.Is(err, jikan.ErrOffline) {
+				return types.ErrorMsg("offline, seasons can't be browsed")
+			}
+			if err != nil {
+				return types.ErrorMsg(err.Error())
+			}
+
+			anime = append(anime, res.Data...)
+			if !res.Pagination.HasNextPage {
+				break
+			}
+			if page == maxPages {
+				log.Printf("Stopped loading %s after %d pages", season, maxPages)
+				truncated = true
+				break
+			}
+		}
+
+		for _, a := range anime {
+			if err := m.dbConfig.CacheAnime(a); err != nil {
+				log.Printf("Could not cache anime %d: %v", a.MalID, err)
+			}
+		}
+		return seasonMessage{season: season, anime: anime, truncated: truncated}
+	})
+}
+
+func (m Model) loadListed() tea.Msg {
+	anime, err := m.dbConfig.DB.GetAllAnime(m.dbConfig.Ctx)
+	if err != nil {
+		return types.ErrorMsg(err.Error())
+	}
+
+	listed := listedMessage{}
+	for _, a := range anime {
+		listed[a.ID] = a.Completion
+	}
+	return listed
+}
+
+// Adds the anime as Plan To Watch, with no start date. Anything already in the list is left alone
+func (m Model) addCmd(anime types.AnimeData) tea.Cmd {
+	if completion, ok := m.listed[int64(anime.MalID)]; ok {
+		return navstack.Cmd(navstack.Notify(fmt.Sprintf("%s is already in your list as %s", anime.Title, completion)))
+	}
+
+	return func() tea.Msg {
+		if err := m.dbConfig.AddAnime(int64(anime.MalID), anime.Title, types.PlanToWatch, sql.NullString{}); err != nil {
+			return types.ErrorMsg(err.Error())
+		}
+		return addedMessage{title: anime.Title}
+	}
+}
+
+// Switches to another season, which starts out loading
+func (m *Model) changeSeason(season Season) tea.Cmd {
+	m.season = season
+	m.groups = nil
+	m.group = 0
+	m.showSpinner = true
+	m.setRows()
+	return tea.Batch(m.spinner.Tick, m.loadSeasonCmd(season))
+}
+
+func (m Model) selected() (types.AnimeData, bool) {
+	if m.group >= len(m.groups) {
+		return types.AnimeData{}, false
+	}
+
+	anime := m.groups[m.group].anime
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(anime) {
+		return types.AnimeData{}, false
+	}
+	return anime[cursor], true
+}
+
+func columns() []table.Column {
+	return []table.Column{
+		{Title: "Id", Width: 8},
+		{Title: "Name", Width: 40},
+		{Title: "Eps", Width: 5},
+		{Title: "Score", Width: 5},
+		{Title: "Starts", Width: 10},
+		{Title: "Broadcast", Width: 24},
+		{Title: "In List", Width: 16},
+	}
+}
+
+// Fills the table with the current group, keeping the cursor where it was if it can
+func (m *Model) setRows() {
+	rows := []table.Row{}
+	if m.group < len(m.groups) {
+		for _, a := range m.groups[m.group].anime {
+			episodes := "?"
+			if a.Episodes > 0 {
+				episodes = strconv.Itoa(a.Episodes)
+			}
+			score := "-"
+			if a.Score > 0 {
+				score = fmt.Sprintf("%.2f", a.Score)
+			}
+			starts := a.Aired.From
+			if len(starts) > len("2006-01-02") {
+				starts = starts[:len("2006-01-02")]
+			}
+			inList := ""
+			if completion, ok := m.listed[int64(a.MalID)]; ok {
+				inList = "✓ " + completion
+			}
+
+			rows = append(rows, table.Row{strconv.Itoa(a.MalID), a.Title, episodes, score, starts, a.Broadcast.String, inList})
+		}
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows(rows)
+	m.table.SetCursor(max(0, min(cursor, len(rows)-1)))
+}
+
+func (m *Model) changeGroup(by int) {
+	if len(m.groups) == 0 {
+		return
+	}
+	m.group = (m.group + by + len(m.groups)) % len(m.groups)
+	m.table.SetCursor(0)
+	m.setRows()
+}
+
+func (m Model) headerView() string {
+	header := seasonStyle.Render(m.season.String())
+	if m.season == m.current {
+		header += " (this season)"
+	}
+	return header + "  ‹ [ ] ›"
+}
+
+func (m Model) groupsView() string {
+	tabs := []string{}
+	for i, g := range m.groups {
+		style := groupStyle
+		if i == m.group {
+			style = selectedGroup
+		}
+		tabs = append(tabs, style.Render(fmt.Sprintf("%s (%d)", g.name, len(g.anime))))
+	}
+	if m.truncated {
+		tabs = append(tabs, groupStyle.Render(fmt.Sprintf("· only the first %d pages were loaded", maxPages)))
+	}
+	return strings.Join(tabs, " ")
+}
+
+func (m *Model) resizeTable() {
+	// Season, type tabs, table borders and header
+	used := 6
+	if m.showHelp {
+		used += lipgloss.Height(m.help.View(SeasonalKeyMap))
+	}
+	m.table.SetHeight(max(1, m.height-used))
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case types.ErrorMsg:
+		// Shown in the status bar by navstack
+		m.showSpinner = false
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resizeTable()
+		return m, nil
+	case seasonMessage:
+		// Already moved on to another season
+		if msg.season != m.season {
+			return m, nil
+		}
+		m.groups = groupByType(msg.anime)
+		m.truncated = msg.truncated
+		m.group = 0
+		m.showSpinner = false
+		m.table.SetCursor(0)
+		m.setRows()
+		return m, nil
+	case listedMessage:
+		m.listed = msg
+		m.setRows()
+		return m, nil
+	case addedMessage:
+		return m, tea.Batch(m.loadListed, navstack.Cmd(navstack.Notify(fmt.Sprintf("Added %s as %s", msg.title, types.PlanToWatch))))
+	case navstack.ResumeNavigation:
+		// Tracking might have changed on the info page
+		return m, m.loadListed
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, SeasonalKeyMap.Esc):
+			return m, navstack.Cmd(navstack.PopNavigation{})
+		case key.Matches(msg, SeasonalKeyMap.Help):
+			m.showHelp = !m.showHelp
+			m.resizeTable()
+			return m, nil
+		case key.Matches(msg, SeasonalKeyMap.NextSeason):
+			return m, m.changeSeason(m.season.Add(1))
+		case key.Matches(msg, SeasonalKeyMap.PrevSeason):
+			return m, m.changeSeason(m.season.Add(-1))
+		case key.Matches(msg, SeasonalKeyMap.ThisSeason):
+			if m.season == m.current {
+				return m, nil
+			}
+			return m, m.changeSeason(m.current)
+		case key.Matches(msg, SeasonalKeyMap.NextGroup):
+			m.changeGroup(1)
+			return m, nil
+		case key.Matches(msg, SeasonalKeyMap.PrevGroup):
+			m.changeGroup(-1)
+			return m, nil
+		case key.Matches(msg, SeasonalKeyMap.Add):
+			if anime, ok := m.selected(); ok {
+				return m, m.addCmd(anime)
+			}
+			return m, nil
+		case key.Matches(msg, SeasonalKeyMap.Select):
+			anime, ok := m.selected()
+			if !ok {
+				return m, nil
+			}
+			// The season already has the full details, so there's nothing to fetch
+			return m, tea.Sequence(
+				navstack.Cmd(navstack.PushNavigation{Item: animeinfo.New(m.dbConfig, int64(anime.MalID))}),
+				navstack.Cmd(types.AnimeDataMessage{Data: anime}),
+			)
+		}
+	}
+
+	if m.showSpinner {
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m Model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	render := m.headerView() + "\n"
+	if m.showSpinner {
+		render += "\n" + m.spinner.View()
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, render)
+	}
+
+	if len(m.groups) == 0 {
+		render += "\nNothing found for this season. Press [ or ] to try another"
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, render)
+	}
+
+	render += m.groupsView() + "\n"
+	render += baseStyle.Render(m.table.View()) + "\n"
+	if m.showHelp {
+		render += m.help.View(SeasonalKeyMap)
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, 0, render)
+}
@@ -0,0 +1,84 @@
+package seasonal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saubuny/haru/types"
+)
+
+// MAL's seasons, in the order they happen each year
+var seasonNames = []string{"winter", "spring", "summer", "fall"}
+
+type Season struct {
+	Year int
+	// One of winter, spring, summer or fall, which is how Jikan names them
+	Name string
+}
+
+// The season t falls in. Winter is January to March, spring April to June and so on
+func SeasonOf(t time.Time) Season {
+	return Season{Year: t.Year(), Name: seasonNames[(int(t.Month())-1)/3]}
+}
+
+func (s Season) index() int {
+	for i, name := range seasonNames {
+		if name == s.Name {
+			return i
+		}
+	}
+	return 0
+}
+
+// Moves by some number of seasons, crossing into other years as needed
+func (s Season) Add(seasons int) Season {
+	i := s.Year*len(seasonNames) + s.index() + seasons
+	return Season{Year: i / len(seasonNames), Name: seasonNames[i%len(seasonNames)]}
+}
+
+// e.g. Fall 2024
+func (s Season) String() string {
+	return fmt.Sprintf("%s %d", strings.ToUpper(s.Name[:1])+s.Name[1:], s.Year)
+}
+
+// Anime of one type in a season
+type group struct {
+	name  string
+	anime []types.AnimeData
+}
+
+// TV, ONA and movies get their own groups, and everything else (OVAs, specials, music videos) is lumped together.
+// Jikan sometimes repeats anime across pages, so duplicates are dropped. The most popular anime in each group come first
+func groupByType(anime []types.AnimeData) []group {
+	names := []string{"TV", "ONA", "Movie", "Other"}
+	grouped := map[string][]types.AnimeData{}
+
+	seen := map[int]bool{}
+	for _, a := range anime {
+		if seen[a.MalID] {
+			continue
+		}
+		seen[a.MalID] = true
+
+		name := "Other"
+		switch a.Type {
+		case "TV", "ONA", "Movie":
+			name = a.Type
+		}
+		grouped[name] = append(grouped[name], a)
+	}
+
+	groups := []group{}
+	for _, name := range names {
+		if len(grouped[name]) == 0 {
+			continue
+		}
+		sort.SliceStable(grouped[name], func(i, j int) bool {
+			return grouped[name][i].Members > grouped[name][j].Members
+		})
+		groups = append(groups, group{name: name, anime: grouped[name]})
+	}
+	return groups
+}
@@ -0,0 +1,72 @@
+package seasonal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saubuny/haru/types"
+)
+
+func TestSeasonOf(t *testing.T) {
+	tests := map[string]Season{
+		"2024-01-01": {2024, "winter"},
+		"2024-03-31": {2024, "winter"},
+		"2024-04-01": {2024, "spring"},
+		"2024-08-15": {2024, "summer"},
+		"2024-12-31": {2024, "fall"},
+	}
+
+	for date, expected := range tests {
+		day, _ := time.Parse("2006-01-02", date)
+		if got := SeasonOf(day); got != expected {
+			t.Errorf("%s: expected %v, got %v", date, expected, got)
+		}
+	}
+}
+
+func TestSeasonAdd(t *testing.T) {
+	fall := Season{2024, "fall"}
+
+	if next := fall.Add(1); next != (Season{2025, "winter"}) {
+		t.Fatalf("expected winter 2025 after fall 2024, got %v", next)
+	}
+	if prev := (Season{2025, "winter"}).Add(-1); prev != fall {
+		t.Fatalf("expected fall 2024 before winter 2025, got %v", prev)
+	}
+	if back := fall.Add(-9); back != (Season{2022, "summer"}) {
+		t.Fatalf("expected summer 2022 nine seasons back, got %v", back)
+	}
+	if s := fall.String(); s != "Fall 2024" {
+		t.Fatalf("unexpected name %q", s)
+	}
+}
+
+func TestGroupByType(t *testing.T) {
+	anime := []types.AnimeData{
+		{MalID: 1, Type: "TV", Members: 10},
+		{MalID: 2, Type: "Movie", Members: 5},
+		{MalID: 3, Type: "TV", Members: 50},
+		{MalID: 4, Type: "OVA"},
+		{MalID: 5, Type: "Special"},
+		{MalID: 3, Type: "TV", Members: 50},
+	}
+
+	got := map[string][]int{}
+	names := []string{}
+	for _, g := range groupByType(anime) {
+		names = append(names, g.name)
+		for _, a := range g.anime {
+			got[g.name] = append(got[g.name], a.MalID)
+		}
+	}
+
+	// Empty groups are left out, and the most popular come first without repeats
+	if !reflect.DeepEqual(names, []string{"TV", "Movie", "Other"}) {
+		t.Fatalf("unexpected groups %v", names)
+	}
+	expected := map[string][]int{"TV": {3, 1}, "Movie": {2}, "Other": {4, 5}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
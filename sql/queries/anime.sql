@@ -6,11 +6,29 @@ WHERE id = ? LIMIT 1;
 SELECT * FROM anime;
 
 -- name: UpdateAnime :exec
-UPDATE anime SET startDate = ?, updatedDate = ?, completion = ? WHERE id = ?;
+UPDATE anime SET startDate = ?, updatedDate = ?, completion = ?, watchedEpisodes = ?, score = ?, finishDate = ?, timesWatched = ? WHERE id = ?;
+
+-- name: UpdateAnimeCompletion :exec
+UPDATE anime SET completion = ?, updatedDate = ? WHERE id = ?;
+
+-- name: UpdateAnimeEpisodes :exec
+UPDATE anime SET watchedEpisodes = ?, updatedDate = ? WHERE id = ?;
+
+-- name: UpdateAnimeScore :exec
+UPDATE anime SET score = ?, updatedDate = ? WHERE id = ?;
+
+-- name: UpdateAnimeFinishDate :exec
+UPDATE anime SET finishDate = ?, updatedDate = ? WHERE id = ?;
+
+-- name: UpdateAnimeStartDate :exec
+UPDATE anime SET startDate = ?, updatedDate = ? WHERE id = ?;
+
+-- name: UpdateAnimeTimesWatched :exec
+UPDATE anime SET timesWatched = ?, updatedDate = ? WHERE id = ?;
 
 -- name: CreateAnime :one
-INSERT INTO anime (id, title, startDate, updatedDate, completion)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO anime (id, title, startDate, updatedDate, completion, watchedEpisodes, score, finishDate, timesWatched)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: DeleteAnime :exec
@@ -0,0 +1,58 @@
+-- name: GetMetadata :one
+SELECT * FROM metadata
+WHERE id = ? LIMIT 1;
+
+-- name: GetAllMetadata :many
+SELECT * FROM metadata;
+
+-- name: UpsertMetadata :exec
+INSERT INTO metadata (id, title, titleEnglish, titleJapanese, synonyms, synopsis, type, episodes, status, airing, airedFrom, airedTo, genres, studios, imageUrl, data, fetchedAt)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+    title = excluded.title,
+    titleEnglish = excluded.titleEnglish,
+    titleJapanese = excluded.titleJapanese,
+    synonyms = excluded.synonyms,
+    synopsis = excluded.synopsis,
+    type = excluded.type,
+    episodes = excluded.episodes,
+    status = excluded.status,
+    airing = excluded.airing,
+    airedFrom = excluded.airedFrom,
+    airedTo = excluded.airedTo,
+    genres = excluded.genres,
+    studios = excluded.studios,
+    imageUrl = excluded.imageUrl,
+    data = excluded.data,
+    fetchedAt = excluded.fetchedAt;
+
+-- name: GetStaleMetadataIDs :many
+SELECT id FROM metadata WHERE fetchedAt < ?;
+
+-- name: GetAnimeIDsWithoutMetadata :many
+SELECT anime.id FROM anime
+LEFT JOIN metadata ON metadata.id = anime.id
+WHERE metadata.id IS NULL;
+
+-- name: DeleteMetadataBefore :execrows
+DELETE FROM metadata WHERE fetchedAt < ?;
+
+-- name: DeleteAllMetadata :execrows
+DELETE FROM metadata;
+
+-- name: SearchMetadata :many
+SELECT * FROM metadata
+WHERE title LIKE sqlc.arg(pattern) ESCAPE '\'
+    OR titleEnglish LIKE sqlc.arg(pattern) ESCAPE '\'
+    OR titleJapanese LIKE sqlc.arg(pattern) ESCAPE '\'
+    OR synonyms LIKE sqlc.arg(pattern) ESCAPE '\'
+ORDER BY title
+LIMIT sqlc.arg(max_results);
+
+-- name: GetTopMetadata :many
+SELECT * FROM metadata
+ORDER BY json_extract(data, '$.score') DESC
+LIMIT ?;
+
+-- name: GetMetadataTitles :many
+SELECT id, titleEnglish, titleJapanese, synonyms FROM metadata;
@@ -0,0 +1,14 @@
+-- name: CreatePendingLookup :exec
+INSERT INTO pending_lookups (title, name, link, completion, queuedAt, watchedEpisodes, score, timesWatched, startDate, finishDate)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetPendingLookups :many
+SELECT * FROM pending_lookups
+ORDER BY id;
+
+-- name: CountPendingLookups :one
+SELECT COUNT(*) FROM pending_lookups;
+
+-- name: DeletePendingLookup :exec
+DELETE FROM pending_lookups
+WHERE id = ?;
@@ -0,0 +1,20 @@
+-- name: SearchListedAnime :many
+SELECT anime.* FROM anime_search
+JOIN anime ON anime.id = anime_search.rowid
+WHERE anime_search MATCH sqlc.arg(query)
+ORDER BY bm25(anime_search, 10.0, 5.0, 1.0)
+LIMIT sqlc.arg(max_results);
+
+-- name: SearchIndexedMetadata :many
+SELECT metadata.* FROM anime_search
+JOIN metadata ON metadata.id = anime_search.rowid
+WHERE anime_search MATCH sqlc.arg(query)
+ORDER BY bm25(anime_search, 10.0, 5.0, 1.0)
+LIMIT sqlc.arg(max_results);
+
+-- name: ClearSearchIndex :exec
+DELETE FROM anime_search;
+
+-- name: RebuildSearchIndex :exec
+INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+SELECT id, title, otherTitles, synopsis FROM anime_search_source;
@@ -0,0 +1,4 @@
+ALTER TABLE anime ADD COLUMN watchedEpisodes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE anime ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE anime ADD COLUMN finishDate TEXT NOT NULL DEFAULT '';
+ALTER TABLE anime ADD COLUMN timesWatched INTEGER NOT NULL DEFAULT 0;
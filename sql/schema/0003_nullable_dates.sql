@@ -0,0 +1,41 @@
+-- Unknown dates are stored as NULL instead of '' or MAL's 0000-00-00, and partial dates like 2020-05-00 become 2020-05.
+-- SQLite can't change a column's constraints, so the table gets rebuilt
+
+CREATE TABLE anime_new (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    startDate TEXT,
+    updatedDate TEXT NOT NULL,
+    completion TEXT NOT NULL,
+    watchedEpisodes INTEGER NOT NULL DEFAULT 0,
+    score INTEGER NOT NULL DEFAULT 0,
+    finishDate TEXT,
+    timesWatched INTEGER NOT NULL DEFAULT 0
+);
+
+INSERT INTO anime_new (id, title, startDate, updatedDate, completion, watchedEpisodes, score, finishDate, timesWatched)
+SELECT
+    id,
+    title,
+    CASE
+        WHEN startDate IN ('', '0000-00-00') OR startDate LIKE '0000-%' THEN NULL
+        WHEN startDate LIKE '____-00-00' THEN substr(startDate, 1, 4)
+        WHEN startDate LIKE '____-__-00' THEN substr(startDate, 1, 7)
+        ELSE startDate
+    END,
+    updatedDate,
+    completion,
+    watchedEpisodes,
+    score,
+    CASE
+        WHEN finishDate IN ('', '0000-00-00') OR finishDate LIKE '0000-%' THEN NULL
+        WHEN finishDate LIKE '____-00-00' THEN substr(finishDate, 1, 4)
+        WHEN finishDate LIKE '____-__-00' THEN substr(finishDate, 1, 7)
+        ELSE finishDate
+    END,
+    timesWatched
+FROM anime;
+
+DROP TABLE anime;
+
+ALTER TABLE anime_new RENAME TO anime;
@@ -0,0 +1,22 @@
+-- Anime details fetched from Jikan. The columns haru searches and displays are pulled out, and data keeps the full response.
+-- synonyms is newline separated, genres and studios are comma separated, fetchedAt is RFC 3339 in UTC so it sorts as text
+
+CREATE TABLE metadata (
+    id INTEGER PRIMARY KEY,
+    title TEXT NOT NULL,
+    titleEnglish TEXT NOT NULL,
+    titleJapanese TEXT NOT NULL,
+    synonyms TEXT NOT NULL,
+    synopsis TEXT NOT NULL,
+    type TEXT NOT NULL,
+    episodes INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    airing BOOLEAN NOT NULL,
+    airedFrom TEXT,
+    airedTo TEXT,
+    genres TEXT NOT NULL,
+    studios TEXT NOT NULL,
+    imageUrl TEXT NOT NULL,
+    data TEXT NOT NULL,
+    fetchedAt TEXT NOT NULL
+);
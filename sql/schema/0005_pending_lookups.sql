@@ -0,0 +1,11 @@
+-- Imported entries that couldn't be matched to a MAL ID because Jikan was unreachable. They are looked up again once haru is back online.
+-- title is what gets searched for, name and link are what the import file called the entry, queuedAt is RFC 3339 in UTC
+
+CREATE TABLE pending_lookups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    name TEXT NOT NULL,
+    link TEXT NOT NULL,
+    completion TEXT NOT NULL,
+    queuedAt TEXT NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Tracking for entries waiting on a lookup, so an import that had to queue an entry doesn't lose its progress. Hianime exports don't have any,
+-- so theirs stay at the defaults
+
+ALTER TABLE pending_lookups ADD COLUMN watchedEpisodes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pending_lookups ADD COLUMN score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pending_lookups ADD COLUMN timesWatched INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE pending_lookups ADD COLUMN startDate TEXT;
+ALTER TABLE pending_lookups ADD COLUMN finishDate TEXT;
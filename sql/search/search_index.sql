@@ -0,0 +1,65 @@
+-- Full text index over the list and the metadata cache, one row per MAL ID. This isn't a numbered migration because it needs SQLite built with FTS5
+-- (go build -tags sqlite_fts5), so it's only set up when that's available. Everything uses IF NOT EXISTS since it's run on every startup.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS anime_search USING fts5(
+    title,
+    otherTitles,
+    synopsis,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- What each row of the index should contain: the title from the list (or the cache if it isn't listed), every other cached title, and the synopsis
+CREATE VIEW IF NOT EXISTS anime_search_source AS
+SELECT
+    anime.id AS id,
+    anime.title AS title,
+    COALESCE(metadata.title || ' ' || metadata.titleEnglish || ' ' || metadata.titleJapanese || ' ' || replace(metadata.synonyms, char(10), ' '), '') AS otherTitles,
+    COALESCE(metadata.synopsis, '') AS synopsis
+FROM anime
+LEFT JOIN metadata ON metadata.id = anime.id
+UNION ALL
+SELECT
+    metadata.id,
+    metadata.title,
+    metadata.titleEnglish || ' ' || metadata.titleJapanese || ' ' || replace(metadata.synonyms, char(10), ' '),
+    metadata.synopsis
+FROM metadata
+WHERE metadata.id NOT IN (SELECT id FROM anime);
+
+-- Any change to either table rebuilds that anime's row from the view
+
+CREATE TRIGGER IF NOT EXISTS anime_search_anime_insert AFTER INSERT ON anime BEGIN
+    DELETE FROM anime_search WHERE rowid = new.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS anime_search_anime_update AFTER UPDATE OF title ON anime BEGIN
+    DELETE FROM anime_search WHERE rowid = new.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS anime_search_anime_delete AFTER DELETE ON anime BEGIN
+    DELETE FROM anime_search WHERE rowid = old.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS anime_search_metadata_insert AFTER INSERT ON metadata BEGIN
+    DELETE FROM anime_search WHERE rowid = new.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS anime_search_metadata_update AFTER UPDATE ON metadata BEGIN
+    DELETE FROM anime_search WHERE rowid = new.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS anime_search_metadata_delete AFTER DELETE ON metadata BEGIN
+    DELETE FROM anime_search WHERE rowid = old.id;
+    INSERT INTO anime_search (rowid, title, otherTitles, synopsis)
+    SELECT id, title, otherTitles, synopsis FROM anime_search_source WHERE id = old.id;
+END;
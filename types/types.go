@@ -148,26 +148,59 @@ type AnimeListResponse struct {
 	} `json:"pagination"`
 }
 
+// MAL's list export, which is also what MAL's importer takes. Other trackers like Kitsu export in this format too
 type Myanimelist struct {
-	XMLName xml.Name `xml:"myanimelist"`
-	Text    string   `xml:",chardata"`
-	Myinfo  struct {
-		Text           string `xml:",chardata"`
-		UserExportType string `xml:"user_export_type"`
-	} `xml:"myinfo"`
-	Anime []struct {
-		Text              string `xml:",chardata"`
-		SeriesTitle       string `xml:"series_title"`
-		SeriesAnimedbID   string `xml:"series_animedb_id"`
-		MyWatchedEpisodes string `xml:"my_watched_episodes"`
-		MyReadVolumes     string `xml:"my_read_volumes"`
-		MyStartDate       string `xml:"my_start_date"`
-		MyStatus          string `xml:"my_status"`
-		MyTimesWatched    string `xml:"my_times_watched"`
-		UpdateOnImport    string `xml:"update_on_import"`
-		MyFinishDate      string `xml:"my_finish_date"`
-		MyScore           string `xml:"my_score"`
-	} `xml:"anime"`
+	XMLName xml.Name           `xml:"myanimelist"`
+	Text    string             `xml:",chardata"`
+	Myinfo  MyanimelistInfo    `xml:"myinfo"`
+	Anime   []MyanimelistAnime `xml:"anime"`
+}
+
+type MyanimelistInfo struct {
+	Text           string `xml:",chardata"`
+	UserExportType string `xml:"user_export_type"` // 1 for anime lists
+
+	// Only in MAL's own exports, and left out when empty
+	UserTotalAnime       string `xml:"user_total_anime,omitempty"`
+	UserTotalWatching    string `xml:"user_total_watching,omitempty"`
+	UserTotalCompleted   string `xml:"user_total_completed,omitempty"`
+	UserTotalOnhold      string `xml:"user_total_onhold,omitempty"`
+	UserTotalDropped     string `xml:"user_total_dropped,omitempty"`
+	UserTotalPlantowatch string `xml:"user_total_plantowatch,omitempty"`
+}
+
+type MyanimelistAnime struct {
+	Text              string `xml:",chardata"`
+	SeriesAnimedbID   string `xml:"series_animedb_id"`
+	SeriesTitle       string `xml:"series_title"`
+	MyWatchedEpisodes string `xml:"my_watched_episodes"`
+	MyReadVolumes     string `xml:"my_read_volumes,omitempty"`
+	MyStartDate       string `xml:"my_start_date"`
+	MyFinishDate      string `xml:"my_finish_date"`
+	MyScore           string `xml:"my_score"`
+	MyStatus          string `xml:"my_status"` // Watching, Completed, On-Hold, Dropped or Plan to Watch
+	MyTimesWatched    string `xml:"my_times_watched"`
+	UpdateOnImport    string `xml:"update_on_import"` // 1 to overwrite entries already on MAL
+}
+
+// haru's own export, which keeps everything in the list. The version only goes up when the meaning of an existing field changes, and new fields are
+// always optional, so files from older versions can still be imported
+type HaruList struct {
+	Version  int         `json:"version"`
+	Exported string      `json:"exported"` // RFC 3339
+	Anime    []HaruAnime `json:"anime"`
+}
+
+type HaruAnime struct {
+	ID              int64   `json:"id"` // MAL ID
+	Title           string  `json:"title"`
+	Completion      string  `json:"completion"` // Watching, Completed, On Hold, Dropped or Plan To Watch
+	WatchedEpisodes int64   `json:"watched_episodes"`
+	Score           int64   `json:"score"` // 0 for no score, otherwise 1-10
+	TimesWatched    int64   `json:"times_watched"`
+	StartDate       *string `json:"start_date"` // YYYY-MM-DD, YYYY-MM or YYYY, or null if unknown
+	FinishDate      *string `json:"finish_date"`
+	UpdatedDate     string  `json:"updated_date"` // YYYY-MM-DD
 }
 
 type HiAnimeList struct {
@@ -186,3 +219,53 @@ type HiAnimeList struct {
 		} `xml:"data"`
 	} `xml:"folder"`
 }
+
+// An AniList list export, which is the same as the MediaListCollection from AniList's GraphQL API. Both the raw API response (wrapped in
+// data.MediaListCollection) and the collection on its own are accepted
+type AniList struct {
+	Data struct {
+		MediaListCollection AniListCollection `json:"MediaListCollection"`
+	} `json:"data"`
+	AniListCollection
+}
+
+type AniListCollection struct {
+	User struct {
+		Name             string `json:"name"`
+		MediaListOptions struct {
+			ScoreFormat string `json:"scoreFormat"` // POINT_100, POINT_10_DECIMAL, POINT_10, POINT_5 or POINT_3
+		} `json:"mediaListOptions"`
+	} `json:"user"`
+	Lists []struct {
+		Name         string         `json:"name"`
+		IsCustomList bool           `json:"isCustomList"`
+		Entries      []AniListEntry `json:"entries"`
+	} `json:"lists"`
+}
+
+type AniListEntry struct {
+	Status      string      `json:"status"` // CURRENT, PLANNING, COMPLETED, PAUSED, DROPPED or REPEATING
+	Score       float64     `json:"score"`
+	Progress    int         `json:"progress"`
+	Repeat      int         `json:"repeat"`
+	StartedAt   AniListDate `json:"startedAt"`
+	CompletedAt AniListDate `json:"completedAt"`
+	Media       struct {
+		ID    int    `json:"id"`
+		IDMal *int   `json:"idMal"` // null for anime that aren't on MAL
+		Type  string `json:"type"`  // ANIME or MANGA
+		Title struct {
+			Romaji        string `json:"romaji"`
+			English       string `json:"english"`
+			Native        string `json:"native"`
+			UserPreferred string `json:"userPreferred"`
+		} `json:"title"`
+	} `json:"media"`
+}
+
+// Any part can be null when it isn't known
+type AniListDate struct {
+	Year  *int `json:"year"`
+	Month *int `json:"month"`
+	Day   *int `json:"day"`
+}